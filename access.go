@@ -0,0 +1,31 @@
+package osfs
+
+// AccessMode selects the permissions checked by Access. The zero value,
+// AccessExists, checks only that the file exists. The values match those of
+// the POSIX F_OK, X_OK, W_OK and R_OK constants.
+type AccessMode uint32
+
+const (
+	AccessExists  AccessMode = 0
+	AccessExecute AccessMode = 1
+	AccessWrite   AccessMode = 2
+	AccessRead    AccessMode = 4
+)
+
+// Access checks whether the calling process may access the named file in
+// the given mode, which is AccessExists or a combination of AccessRead,
+// AccessWrite and AccessExecute. Unlike inspecting the FileMode from Stat,
+// it lets the operating system decide, so ACLs, read-only mounts and
+// network filesystems are taken into account. It returns nil if access is
+// granted and an *os.PathError otherwise. Symbolic links are followed.
+//
+// On Unix the check is made with access(2) and so uses the real rather than
+// the effective user and group IDs. On Windows the file is opened with the
+// requested rights and closed again, which never modifies it.
+func (fs *FileSystem) Access(name string, mode AccessMode) error {
+	path, err := fs.fixPathErr("access", name)
+	if err != nil {
+		return err
+	}
+	return access(path, mode)
+}
@@ -0,0 +1,13 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris && !windows
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris,!windows
+
+package osfs
+
+import "os"
+
+func access(path string, mode AccessMode) error {
+	if _, err := os.Stat(path); err != nil || mode == AccessExists {
+		return err
+	}
+	return &os.PathError{Op: "access", Path: path, Err: ErrNotSupported}
+}
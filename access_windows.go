@@ -0,0 +1,37 @@
+package osfs
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+func access(path string, mode AccessMode) error {
+	if mode == AccessExists {
+		_, err := os.Stat(path)
+		return err
+	}
+	var rights uint32
+	if mode&AccessRead != 0 {
+		rights |= windows.GENERIC_READ
+	}
+	if mode&AccessWrite != 0 {
+		rights |= windows.GENERIC_WRITE
+	}
+	if mode&AccessExecute != 0 {
+		rights |= windows.GENERIC_EXECUTE
+	}
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return &os.PathError{Op: "access", Path: path, Err: err}
+	}
+	// FILE_FLAG_BACKUP_SEMANTICS lets directories be opened as well.
+	h, err := windows.CreateFile(p, rights,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE,
+		nil, windows.OPEN_EXISTING, windows.FILE_FLAG_BACKUP_SEMANTICS, 0)
+	if err != nil {
+		return &os.PathError{Op: "access", Path: path, Err: err}
+	}
+	windows.CloseHandle(h)
+	return nil
+}
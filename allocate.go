@@ -0,0 +1,15 @@
+package osfs
+
+// Allocate reserves disk space for the byte range [offset, offset+length) of
+// the file, so that later writes to it do not fail for lack of space and
+// the blocks are more likely to be contiguous. The file is extended to
+// offset+length if it is shorter, as with posix_fallocate(3); unlike
+// Truncate, the new range is allocated up front rather than left sparse.
+//
+// Allocate uses fallocate(2) on Linux and F_PREALLOCATE on macOS. On Windows
+// it extends the file with SetEndOfFile, which allocates the clusters. On
+// other platforms, and on filesystems that cannot preallocate, it returns
+// an error wrapping ErrNotSupported.
+func (f *File) Allocate(offset, length int64) error {
+	return allocateFile(f.f, offset, length)
+}
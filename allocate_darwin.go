@@ -0,0 +1,46 @@
+package osfs
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+func allocateFile(f *os.File, offset, length int64) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	end := offset + length
+	if end <= info.Size() {
+		return nil
+	}
+
+	// F_PREALLOCATE allocates relative to the physical end of the file.
+	// Ask for contiguous space first and settle for any.
+	store := unix.Fstore_t{
+		Flags:   unix.F_ALLOCATECONTIG | unix.F_ALLOCATEALL,
+		Posmode: unix.F_PEOFPOSMODE,
+		Length:  end - info.Size(),
+	}
+	if err := fcntlFstore(f, &store); err != nil {
+		store.Flags = unix.F_ALLOCATEALL
+		if err := fcntlFstore(f, &store); err != nil {
+			if err == unix.ENOTSUP {
+				err = ErrNotSupported
+			}
+			return &os.PathError{Op: "fcntl", Path: f.Name(), Err: err}
+		}
+	}
+	return f.Truncate(end)
+}
+
+func fcntlFstore(f *os.File, store *unix.Fstore_t) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_FCNTL, f.Fd(), unix.F_PREALLOCATE, uintptr(unsafe.Pointer(store)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
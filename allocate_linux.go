@@ -0,0 +1,22 @@
+package osfs
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+func allocateFile(f *os.File, offset, length int64) error {
+	for {
+		err := unix.Fallocate(int(f.Fd()), 0, offset, length)
+		switch err {
+		case nil:
+			return nil
+		case unix.EINTR:
+			continue
+		case unix.EOPNOTSUPP:
+			err = ErrNotSupported
+		}
+		return &os.PathError{Op: "fallocate", Path: f.Name(), Err: err}
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !darwin && !linux && !windows
+// +build !darwin,!linux,!windows
+
+package osfs
+
+import "os"
+
+func allocateFile(f *os.File, offset, length int64) error {
+	return &os.PathError{Op: "allocate", Path: f.Name(), Err: ErrNotSupported}
+}
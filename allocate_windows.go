@@ -0,0 +1,14 @@
+package osfs
+
+import "os"
+
+func allocateFile(f *os.File, offset, length int64) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if end := offset + length; end > info.Size() {
+		return f.Truncate(end)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package osfs
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic writes data to the named file so that readers and crashes
+// only ever see the old contents or the new ones, never a mix. The data is
+// written to a temporary file in the same directory, named after
+// TempPattern, which is flushed to disk and then renamed over name. On Unix
+// the directory is flushed as well so the rename itself is durable. The new
+// file gets exactly the permission bits perm. On any error the temporary
+// file is removed and name is left untouched.
+func (fs *FileSystem) WriteFileAtomic(name string, data []byte, perm os.FileMode) error {
+	path, err := fs.fixPathErr("open", name)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	f, err := fs.createTemp(dir, "")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(tmp)
+		}
+		fs.temps.remove(tmp)
+	}()
+
+	if _, err = f.Write(data); err != nil {
+		return err
+	}
+	if err = f.Chmod(perm); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = replaceFile(tmp, path); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// SyncDir flushes the metadata of the named directory to disk, so that
+// files created, renamed or removed in it survive a crash. File.Sync only
+// covers a file's own contents. On Windows, where directories cannot be
+// flushed, SyncDir does nothing and returns nil.
+func (fs *FileSystem) SyncDir(name string) error {
+	path, err := fs.fixPathErr("open", name)
+	if err != nil {
+		return err
+	}
+	return syncDir(path)
+}
@@ -0,0 +1,42 @@
+package osfs
+
+// Windows file attributes for GetAttributes and SetAttributes.
+const (
+	AttrReadOnly          uint32 = 0x00000001 // FILE_ATTRIBUTE_READONLY
+	AttrHidden            uint32 = 0x00000002 // FILE_ATTRIBUTE_HIDDEN
+	AttrSystem            uint32 = 0x00000004 // FILE_ATTRIBUTE_SYSTEM
+	AttrDirectory         uint32 = 0x00000010 // FILE_ATTRIBUTE_DIRECTORY, read only
+	AttrArchive           uint32 = 0x00000020 // FILE_ATTRIBUTE_ARCHIVE
+	AttrNormal            uint32 = 0x00000080 // FILE_ATTRIBUTE_NORMAL, no other attributes
+	AttrTemporary         uint32 = 0x00000100 // FILE_ATTRIBUTE_TEMPORARY
+	AttrReparsePoint      uint32 = 0x00000400 // FILE_ATTRIBUTE_REPARSE_POINT, read only
+	AttrCompressed        uint32 = 0x00000800 // FILE_ATTRIBUTE_COMPRESSED, read only
+	AttrOffline           uint32 = 0x00001000 // FILE_ATTRIBUTE_OFFLINE
+	AttrNotContentIndexed uint32 = 0x00002000 // FILE_ATTRIBUTE_NOT_CONTENT_INDEXED
+	AttrEncrypted         uint32 = 0x00004000 // FILE_ATTRIBUTE_ENCRYPTED, read only
+)
+
+// GetAttributes returns the Windows file attributes of the named file, such
+// as AttrHidden and AttrSystem, which os.FileMode cannot represent. A final
+// symbolic link is not followed. On other platforms it returns an error
+// wrapping ErrNotSupported.
+func (fs *FileSystem) GetAttributes(name string) (uint32, error) {
+	path, err := fs.fixPathErr("getattributes", name)
+	if err != nil {
+		return 0, err
+	}
+	return getAttributes(path)
+}
+
+// SetAttributes replaces the Windows file attributes of the named file with
+// attrs. Attributes marked read only above are managed by the system and
+// are ignored. To change one attribute, combine it with the current set
+// from GetAttributes. On other platforms it returns an error wrapping
+// ErrNotSupported.
+func (fs *FileSystem) SetAttributes(name string, attrs uint32) error {
+	path, err := fs.fixPathErr("setattributes", name)
+	if err != nil {
+		return err
+	}
+	return setAttributes(path, attrs)
+}
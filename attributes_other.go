@@ -0,0 +1,14 @@
+//go:build !windows
+// +build !windows
+
+package osfs
+
+import "os"
+
+func getAttributes(path string) (uint32, error) {
+	return 0, &os.PathError{Op: "getattributes", Path: path, Err: ErrNotSupported}
+}
+
+func setAttributes(path string, attrs uint32) error {
+	return &os.PathError{Op: "setattributes", Path: path, Err: ErrNotSupported}
+}
@@ -0,0 +1,30 @@
+package osfs
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+func getAttributes(path string) (uint32, error) {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, &os.PathError{Op: "GetFileAttributes", Path: path, Err: err}
+	}
+	attrs, err := windows.GetFileAttributes(p)
+	if err != nil {
+		return 0, &os.PathError{Op: "GetFileAttributes", Path: path, Err: err}
+	}
+	return attrs, nil
+}
+
+func setAttributes(path string, attrs uint32) error {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return &os.PathError{Op: "SetFileAttributes", Path: path, Err: err}
+	}
+	if err := windows.SetFileAttributes(p, attrs); err != nil {
+		return &os.PathError{Op: "SetFileAttributes", Path: path, Err: err}
+	}
+	return nil
+}
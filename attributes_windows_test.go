@@ -0,0 +1,45 @@
+package osfs_test
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/absfs/osfs"
+)
+
+func TestAttributes(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	if err := fs.WriteFile("file", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	attrs, err := fs.GetAttributes("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attrs&osfs.AttrHidden != 0 {
+		t.Fatalf("new file is hidden: attributes %#x", attrs)
+	}
+
+	hidden := func() bool {
+		t.Helper()
+		info, err := fs.Stat("file")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.Sys().(*syscall.Win32FileAttributeData).FileAttributes&osfs.AttrHidden != 0
+	}
+
+	if err := fs.SetAttributes("file", attrs|osfs.AttrHidden); err != nil {
+		t.Fatal(err)
+	}
+	if !hidden() {
+		t.Error("file not hidden after setting AttrHidden")
+	}
+	if err := fs.SetAttributes("file", attrs&^osfs.AttrHidden); err != nil {
+		t.Fatal(err)
+	}
+	if hidden() {
+		t.Error("file still hidden after clearing AttrHidden")
+	}
+}
@@ -0,0 +1,286 @@
+package osfs
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/absfs/absfs"
+)
+
+// AuditRecord is one line of the log written by an audit FileSystem.
+type AuditRecord struct {
+	Time time.Time `json:"time"`
+	Op   string    `json:"op"`
+	Path string    `json:"path"`
+
+	// NewPath is the destination of a rename or the link of a symlink.
+	NewPath string `json:"newpath,omitempty"`
+
+	// Mode is the permission argument of open, mkdir and chmod.
+	Mode os.FileMode `json:"mode,omitempty"`
+
+	// Flag is the flag argument of open, such as os.O_WRONLY|os.O_APPEND.
+	Flag int `json:"flag,omitempty"`
+
+	// Size is the byte count of a write or the length of a truncate.
+	Size int64 `json:"size,omitempty"`
+
+	// Offset is the file position of a write or writeat, omitted when it
+	// is zero. For a write it is only known if the file can seek.
+	Offset int64 `json:"offset,omitempty"`
+
+	// Error is the text of the error the operation failed with, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// NewAuditFS returns a FileSystem that forwards every call to base and
+// appends an AuditRecord, encoded as one line of JSON, to sink for each
+// operation that modifies the filesystem: opening a file for writing,
+// creating, removing or renaming entries, changing attributes and writing or
+// truncating through an opened File. Chdir is logged too, since it changes
+// the meaning of the relative paths that follow. Paths are logged as passed
+// in. Failed operations are logged with their error.
+//
+// Records are written with a single Write call each. If sink has a
+// Sync() error method, as *os.File does, it is called after every record so
+// the log survives a crash. Errors writing to sink are ignored; use
+// NewAuditFSWithOptions to observe them or to skip syncing. The returned
+// FileSystem also implements absfs.SymLinker; if base does not, its
+// symlink methods fail with absfs.ErrNotImplemented.
+func NewAuditFS(base absfs.FileSystem, sink io.Writer) absfs.FileSystem {
+	return NewAuditFSWithOptions(base, sink, AuditOptions{SyncEachRecord: true})
+}
+
+// AuditOptions configures NewAuditFSWithOptions.
+type AuditOptions struct {
+	// SyncEachRecord calls the Sync() error method of the sink, if it has
+	// one, after every record. Syncing makes each record durable before
+	// the audited call returns, at the cost of a disk flush per call.
+	SyncEachRecord bool
+
+	// OnError, if not nil, is called with every error met while encoding a
+	// record, writing it to the sink or syncing the sink. The audited
+	// operation itself is not affected. It is called with the sink locked,
+	// so it must not use the audit FileSystem.
+	OnError func(error)
+}
+
+// NewAuditFSWithOptions is NewAuditFS configured by opts. NewAuditFS is
+// NewAuditFSWithOptions with SyncEachRecord set.
+func NewAuditFSWithOptions(base absfs.FileSystem, sink io.Writer, opts AuditOptions) absfs.FileSystem {
+	return &auditFS{base: base, sink: sink, opts: opts}
+}
+
+type auditFS struct {
+	base absfs.FileSystem
+	opts AuditOptions
+
+	mu   sync.Mutex
+	sink io.Writer
+}
+
+func (a *auditFS) log(r AuditRecord, err error) {
+	r.Time = time.Now().UTC()
+	if err != nil {
+		r.Error = err.Error()
+	}
+	line, merr := json.Marshal(r)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if merr != nil {
+		a.fail(merr)
+		return
+	}
+	line = append(line, '\n')
+	if _, werr := a.sink.Write(line); werr != nil {
+		a.fail(werr)
+		return
+	}
+	if !a.opts.SyncEachRecord {
+		return
+	}
+	if s, ok := a.sink.(interface{ Sync() error }); ok {
+		if serr := s.Sync(); serr != nil {
+			a.fail(serr)
+		}
+	}
+}
+
+// fail reports an error of the audit log itself to OnError.
+func (a *auditFS) fail(err error) {
+	if a.opts.OnError != nil {
+		a.opts.OnError(err)
+	}
+}
+
+const writeFlags = os.O_WRONLY | os.O_RDWR | os.O_CREATE | os.O_TRUNC | os.O_APPEND
+
+func (a *auditFS) OpenFile(name string, flag int, perm os.FileMode) (absfs.File, error) {
+	f, err := a.base.OpenFile(name, flag, perm)
+	if flag&writeFlags == 0 {
+		return f, err
+	}
+	a.log(AuditRecord{Op: "open", Path: name, Mode: perm, Flag: flag}, err)
+	if err != nil {
+		return f, err
+	}
+	return &auditFile{File: f, a: a}, nil
+}
+
+func (a *auditFS) Open(name string) (absfs.File, error) {
+	return a.base.Open(name)
+}
+
+func (a *auditFS) Create(name string) (absfs.File, error) {
+	f, err := a.base.Create(name)
+	a.log(AuditRecord{Op: "create", Path: name}, err)
+	if err != nil {
+		return f, err
+	}
+	return &auditFile{File: f, a: a}, nil
+}
+
+func (a *auditFS) Mkdir(name string, perm os.FileMode) error {
+	err := a.base.Mkdir(name, perm)
+	a.log(AuditRecord{Op: "mkdir", Path: name, Mode: perm}, err)
+	return err
+}
+
+func (a *auditFS) MkdirAll(name string, perm os.FileMode) error {
+	err := a.base.MkdirAll(name, perm)
+	a.log(AuditRecord{Op: "mkdirall", Path: name, Mode: perm}, err)
+	return err
+}
+
+func (a *auditFS) Remove(name string) error {
+	err := a.base.Remove(name)
+	a.log(AuditRecord{Op: "remove", Path: name}, err)
+	return err
+}
+
+func (a *auditFS) RemoveAll(name string) error {
+	err := a.base.RemoveAll(name)
+	a.log(AuditRecord{Op: "removeall", Path: name}, err)
+	return err
+}
+
+func (a *auditFS) Rename(oldpath, newpath string) error {
+	err := a.base.Rename(oldpath, newpath)
+	a.log(AuditRecord{Op: "rename", Path: oldpath, NewPath: newpath}, err)
+	return err
+}
+
+func (a *auditFS) Chmod(name string, mode os.FileMode) error {
+	err := a.base.Chmod(name, mode)
+	a.log(AuditRecord{Op: "chmod", Path: name, Mode: mode}, err)
+	return err
+}
+
+func (a *auditFS) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	err := a.base.Chtimes(name, atime, mtime)
+	a.log(AuditRecord{Op: "chtimes", Path: name}, err)
+	return err
+}
+
+func (a *auditFS) Chown(name string, uid, gid int) error {
+	err := a.base.Chown(name, uid, gid)
+	a.log(AuditRecord{Op: "chown", Path: name}, err)
+	return err
+}
+
+func (a *auditFS) Truncate(name string, size int64) error {
+	err := a.base.Truncate(name, size)
+	a.log(AuditRecord{Op: "truncate", Path: name, Size: size}, err)
+	return err
+}
+
+func (a *auditFS) Chdir(dir string) error {
+	err := a.base.Chdir(dir)
+	a.log(AuditRecord{Op: "chdir", Path: dir}, err)
+	return err
+}
+
+func (a *auditFS) Stat(name string) (os.FileInfo, error) { return a.base.Stat(name) }
+func (a *auditFS) Separator() uint8                      { return a.base.Separator() }
+func (a *auditFS) ListSeparator() uint8                  { return a.base.ListSeparator() }
+func (a *auditFS) Getwd() (string, error)                { return a.base.Getwd() }
+func (a *auditFS) TempDir() string                       { return a.base.TempDir() }
+
+func (a *auditFS) Lstat(name string) (os.FileInfo, error) {
+	sl, ok := a.base.(absfs.SymLinker)
+	if !ok {
+		return nil, &os.PathError{Op: "lstat", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	return sl.Lstat(name)
+}
+
+func (a *auditFS) Readlink(name string) (string, error) {
+	sl, ok := a.base.(absfs.SymLinker)
+	if !ok {
+		return "", &os.PathError{Op: "readlink", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	return sl.Readlink(name)
+}
+
+func (a *auditFS) Lchown(name string, uid, gid int) error {
+	err := error(&os.PathError{Op: "lchown", Path: name, Err: absfs.ErrNotImplemented})
+	if sl, ok := a.base.(absfs.SymLinker); ok {
+		err = sl.Lchown(name, uid, gid)
+	}
+	a.log(AuditRecord{Op: "lchown", Path: name}, err)
+	return err
+}
+
+func (a *auditFS) Symlink(oldname, newname string) error {
+	err := error(&os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: absfs.ErrNotImplemented})
+	if sl, ok := a.base.(absfs.SymLinker); ok {
+		err = sl.Symlink(oldname, newname)
+	}
+	a.log(AuditRecord{Op: "symlink", Path: oldname, NewPath: newname}, err)
+	return err
+}
+
+// auditFile logs the writes made through a File opened for writing.
+type auditFile struct {
+	absfs.File
+	a *auditFS
+}
+
+func (f *auditFile) Write(b []byte) (int, error) {
+	n, err := f.File.Write(b)
+	f.a.log(AuditRecord{Op: "write", Path: f.Name(), Size: int64(n), Offset: f.offsetBefore(n)}, err)
+	return n, err
+}
+
+func (f *auditFile) WriteAt(b []byte, off int64) (int, error) {
+	n, err := f.File.WriteAt(b, off)
+	f.a.log(AuditRecord{Op: "writeat", Path: f.Name(), Size: int64(n), Offset: off}, err)
+	return n, err
+}
+
+func (f *auditFile) WriteString(s string) (int, error) {
+	n, err := f.File.WriteString(s)
+	f.a.log(AuditRecord{Op: "write", Path: f.Name(), Size: int64(n), Offset: f.offsetBefore(n)}, err)
+	return n, err
+}
+
+// offsetBefore returns the position a write of n bytes that just finished
+// started at. It is taken from the position after the write, which is also
+// right for files opened with O_APPEND. It is zero if the file cannot seek.
+func (f *auditFile) offsetBefore(n int) int64 {
+	pos, err := f.File.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0
+	}
+	return pos - int64(n)
+}
+
+func (f *auditFile) Truncate(size int64) error {
+	err := f.File.Truncate(size)
+	f.a.log(AuditRecord{Op: "truncate", Path: f.Name(), Size: size}, err)
+	return err
+}
@@ -0,0 +1,172 @@
+package osfs_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/osfs"
+)
+
+func TestAuditFS(t *testing.T) {
+	base, _ := newTempFS(t)
+	var log bytes.Buffer
+	fs := osfs.NewAuditFS(base, &log)
+
+	if err := fs.Mkdir("dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := fs.Create("dir/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	r, err := fs.Open("dir/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	if _, err := fs.Stat("dir/file"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fs.Rename("dir/file", "dir/renamed"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Remove("missing"); err == nil {
+		t.Fatal("removing a missing file succeeded")
+	}
+	if _, ok := fs.(absfs.SymLinker); !ok {
+		t.Error("audit FileSystem does not implement absfs.SymLinker")
+	}
+	if err := fs.RemoveAll("dir"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []osfs.AuditRecord{
+		{Op: "mkdir", Path: "dir", Mode: 0755},
+		{Op: "create", Path: "dir/file"},
+		{Op: "write", Size: 5},
+		{Op: "rename", Path: "dir/file", NewPath: "dir/renamed"},
+		{Op: "remove", Path: "missing"},
+		{Op: "removeall", Path: "dir"},
+	}
+	var got []osfs.AuditRecord
+	s := bufio.NewScanner(&log)
+	for s.Scan() {
+		var r osfs.AuditRecord
+		if err := json.Unmarshal(s.Bytes(), &r); err != nil {
+			t.Fatalf("bad record %q: %v", s.Text(), err)
+		}
+		if r.Time.IsZero() {
+			t.Errorf("record %q has no time", s.Text())
+		}
+		got = append(got, r)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d records, want %d:\n%s", len(got), len(want), log.String())
+	}
+	for i, w := range want {
+		g := got[i]
+		if w.Path == "" {
+			w.Path = g.Path
+		}
+		if g.Op != w.Op || g.Path != w.Path || g.NewPath != w.NewPath || g.Mode != w.Mode || g.Size != w.Size {
+			t.Errorf("record %d = %+v, want %+v", i, g, w)
+		}
+		if (g.Error != "") != (w.Op == "remove") {
+			t.Errorf("record %d has error %q", i, g.Error)
+		}
+	}
+	if !os.IsNotExist(fs.Remove("missing")) {
+		t.Error("audit FileSystem changed the error of Remove")
+	}
+}
+
+// failSink fails every Sync and counts the calls.
+type failSink struct {
+	bytes.Buffer
+	syncs int
+}
+
+func (s *failSink) Sync() error {
+	s.syncs++
+	return errors.New("sync failed")
+}
+
+func TestAuditFSOptions(t *testing.T) {
+	base, _ := newTempFS(t)
+
+	var sink failSink
+	var errs []error
+	fs := osfs.NewAuditFSWithOptions(base, &sink, osfs.AuditOptions{
+		SyncEachRecord: true,
+		OnError:        func(err error) { errs = append(errs, err) },
+	})
+	f, err := fs.Create("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("abc")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt([]byte("abc"), 7); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	flag := os.O_WRONLY | os.O_APPEND
+	if f, err = fs.OpenFile("file", flag, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("de"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if sink.syncs != 5 || len(errs) != 5 {
+		t.Errorf("got %d syncs and %d errors, want 5 each", sink.syncs, len(errs))
+	}
+
+	s := bufio.NewScanner(&sink)
+	var got []osfs.AuditRecord
+	for s.Scan() {
+		var r osfs.AuditRecord
+		if err := json.Unmarshal(s.Bytes(), &r); err != nil {
+			t.Fatalf("bad record %q: %v", s.Text(), err)
+		}
+		got = append(got, r)
+	}
+	want := []osfs.AuditRecord{
+		{Op: "create"},
+		{Op: "write", Size: 3},
+		{Op: "writeat", Size: 3, Offset: 7},
+		{Op: "open", Flag: flag},
+		{Op: "write", Size: 2, Offset: 10},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d records, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Op != w.Op || g.Size != w.Size || g.Offset != w.Offset || g.Flag != w.Flag {
+			t.Errorf("record %d = %+v, want %+v", i, g, w)
+		}
+	}
+
+	// Without SyncEachRecord the sink is never synced.
+	sink = failSink{}
+	fs = osfs.NewAuditFSWithOptions(base, &sink, osfs.AuditOptions{})
+	if err := fs.Mkdir("dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if sink.syncs != 0 || sink.Len() == 0 {
+		t.Errorf("got %d syncs and %d bytes, want no sync and a record", sink.syncs, sink.Len())
+	}
+}
@@ -0,0 +1,75 @@
+package osfs
+
+import (
+	"io"
+	"os"
+)
+
+// DefaultBufferSize is the copy buffer size used when
+// FileSystem.BufferSize is zero and the destination's preferred I/O size is
+// not known.
+const DefaultBufferSize = 32 * 1024
+
+// bufferSize returns the size of the buffer used to copy to dst: BufferSize
+// if set, or else the block size reported by stat for dst, if it is a file.
+func (fs *FileSystem) bufferSize(dst io.Writer) int {
+	if fs.BufferSize > 0 {
+		return fs.BufferSize
+	}
+	var target interface{} = dst
+	if w, ok := dst.(*offsetWriter); ok {
+		target = w.w
+	}
+	if f, ok := target.(interface{ Stat() (os.FileInfo, error) }); ok {
+		if info, err := f.Stat(); err == nil {
+			if size, ok := fileBlockSize(info); ok {
+				return size
+			}
+		}
+	}
+	return DefaultBufferSize
+}
+
+// getBuffer returns a pooled buffer of the given size. Pooled buffers of
+// another size, left over from before BufferSize was changed or from a
+// destination with another block size, are dropped.
+func (fs *FileSystem) getBuffer(size int) *[]byte {
+	if b, ok := fs.buffers.Get().(*[]byte); ok && len(*b) == size {
+		return b
+	}
+	b := make([]byte, size)
+	return &b
+}
+
+func (fs *FileSystem) putBuffer(b *[]byte) {
+	fs.buffers.Put(b)
+}
+
+// copyBuffer copies src to dst through a pooled buffer. The writer and
+// reader are wrapped so that io.CopyBuffer cannot bypass the buffer through
+// ReaderFrom or WriterTo, which would allocate one of its own.
+func (fs *FileSystem) copyBuffer(dst io.Writer, src io.Reader) (int64, error) {
+	b := fs.getBuffer(fs.bufferSize(dst))
+	defer fs.putBuffer(b)
+	return io.CopyBuffer(struct{ io.Writer }{dst}, struct{ io.Reader }{src}, *b)
+}
+
+// WriteReader writes everything read from r to the named file, creating it
+// with permissions perm (before umask) if necessary and truncating it
+// otherwise. It returns the number of bytes written. The data is copied
+// through a buffer from the FileSystem's pool.
+func (fs *FileSystem) WriteReader(name string, r io.Reader, perm os.FileMode) (int64, error) {
+	path, err := fs.fixPathErr("open", name)
+	if err != nil {
+		return 0, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return 0, err
+	}
+	n, err := fs.copyBuffer(f, r)
+	if err1 := f.Close(); err1 != nil && err == nil {
+		err = err1
+	}
+	return n, err
+}
@@ -0,0 +1,348 @@
+package osfs
+
+import (
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/absfs/absfs"
+)
+
+// CachedFS is a FileSystem that remembers the results of Stat, Lstat and
+// directory listings of another. It is returned by Cached.
+type CachedFS struct {
+	base absfs.FileSystem
+	ttl  time.Duration
+
+	mu    sync.Mutex
+	stat  map[string]cacheEntry
+	lstat map[string]cacheEntry
+	dirs  map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	info    os.FileInfo
+	list    []os.FileInfo
+	expires time.Time
+}
+
+// Cached returns a FileSystem that forwards every call to base and
+// remembers successful results of Stat, Lstat and reading a whole
+// directory through Readdir or Readdirnames for ttl. Within that window
+// the same path is answered from memory, so a file modified by other means,
+// including another FileSystem for the same files, can be reported as it
+// was up to ttl earlier. Errors are not cached.
+//
+// Changes made through the returned FileSystem invalidate what they affect:
+// the entries for the changed path, for everything below it, and for the
+// listing and attributes of its parent directory. A file opened for writing
+// invalidates its entries when it is opened and again when it is closed.
+// Invalidate drops entries explicitly. Paths are cached in absolute form,
+// resolved against the working directory of base, and symbolic links are
+// not tracked: changing a link's target leaves entries for paths through
+// the link stale until they expire.
+//
+// The returned FileSystem is safe for concurrent use if base is, and also
+// implements absfs.SymLinker; if base does not, its symlink methods fail
+// with absfs.ErrNotImplemented.
+func Cached(base absfs.FileSystem, ttl time.Duration) *CachedFS {
+	return &CachedFS{
+		base:  base,
+		ttl:   ttl,
+		stat:  make(map[string]cacheEntry),
+		lstat: make(map[string]cacheEntry),
+		dirs:  make(map[string]cacheEntry),
+	}
+}
+
+// key returns the absolute, clean form of name used to index the cache.
+func (c *CachedFS) key(name string) string {
+	name = FromNative(name)
+	if !IsAbs(name) {
+		if wd, err := c.base.Getwd(); err == nil {
+			name = path.Join(FromNative(wd), name)
+		}
+	}
+	return Clean(name)
+}
+
+func (c *CachedFS) get(m map[string]cacheEntry, key string) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := m[key]
+	if ok && time.Now().After(e.expires) {
+		delete(m, key)
+		return e, false
+	}
+	return e, ok
+}
+
+func (c *CachedFS) put(m map[string]cacheEntry, key string, e cacheEntry) {
+	e.expires = time.Now().Add(c.ttl)
+	c.mu.Lock()
+	m[key] = e
+	c.mu.Unlock()
+}
+
+// Invalidate drops the cached entries for name, for every path below it
+// and for the listing and attributes of its parent directory.
+func (c *CachedFS) Invalidate(name string) {
+	c.invalidate(c.key(name))
+}
+
+func (c *CachedFS) invalidate(key string) {
+	below := key + "/"
+	if strings.HasSuffix(key, "/") {
+		below = key
+	}
+	parent := Dir(key)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, m := range []map[string]cacheEntry{c.stat, c.lstat, c.dirs} {
+		for k := range m {
+			if k == key || k == parent || strings.HasPrefix(k, below) {
+				delete(m, k)
+			}
+		}
+	}
+}
+
+// drop removes the cached entries for key alone.
+func (c *CachedFS) drop(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.stat, key)
+	delete(c.lstat, key)
+	delete(c.dirs, key)
+}
+
+func (c *CachedFS) Stat(name string) (os.FileInfo, error) {
+	key := c.key(name)
+	if e, ok := c.get(c.stat, key); ok {
+		return e.info, nil
+	}
+	info, err := c.base.Stat(name)
+	if err == nil {
+		c.put(c.stat, key, cacheEntry{info: info})
+	}
+	return info, err
+}
+
+func (c *CachedFS) Lstat(name string) (os.FileInfo, error) {
+	sl, ok := c.base.(absfs.SymLinker)
+	if !ok {
+		return nil, &os.PathError{Op: "lstat", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	key := c.key(name)
+	if e, ok := c.get(c.lstat, key); ok {
+		return e.info, nil
+	}
+	info, err := sl.Lstat(name)
+	if err == nil {
+		c.put(c.lstat, key, cacheEntry{info: info})
+	}
+	return info, err
+}
+
+func (c *CachedFS) OpenFile(name string, flag int, perm os.FileMode) (absfs.File, error) {
+	key := c.key(name)
+	if flag&writeFlags != 0 {
+		c.invalidate(key)
+		f, err := c.base.OpenFile(name, flag, perm)
+		if err != nil {
+			return nil, err
+		}
+		return &cachedFile{File: f, c: c, key: key}, nil
+	}
+	f, err := c.base.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &cachedDir{File: f, c: c, key: key}, nil
+}
+
+func (c *CachedFS) Open(name string) (absfs.File, error) {
+	return c.OpenFile(name, os.O_RDONLY, 0)
+}
+
+func (c *CachedFS) Create(name string) (absfs.File, error) {
+	key := c.key(name)
+	c.invalidate(key)
+	f, err := c.base.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return &cachedFile{File: f, c: c, key: key}, nil
+}
+
+func (c *CachedFS) Mkdir(name string, perm os.FileMode) error {
+	defer c.Invalidate(name)
+	return c.base.Mkdir(name, perm)
+}
+
+func (c *CachedFS) MkdirAll(name string, perm os.FileMode) error {
+	defer func() {
+		key := c.key(name)
+		c.invalidate(key)
+		// Any missing ancestor is created too, changing the listing of
+		// its own parent.
+		for dir := Dir(key); ; dir = Dir(dir) {
+			c.drop(dir)
+			if Dir(dir) == dir {
+				break
+			}
+		}
+	}()
+	return c.base.MkdirAll(name, perm)
+}
+
+func (c *CachedFS) Remove(name string) error {
+	defer c.Invalidate(name)
+	return c.base.Remove(name)
+}
+
+func (c *CachedFS) RemoveAll(name string) error {
+	defer c.Invalidate(name)
+	return c.base.RemoveAll(name)
+}
+
+func (c *CachedFS) Rename(oldpath, newpath string) error {
+	defer c.Invalidate(newpath)
+	defer c.Invalidate(oldpath)
+	return c.base.Rename(oldpath, newpath)
+}
+
+func (c *CachedFS) Chmod(name string, mode os.FileMode) error {
+	defer c.Invalidate(name)
+	return c.base.Chmod(name, mode)
+}
+
+func (c *CachedFS) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	defer c.Invalidate(name)
+	return c.base.Chtimes(name, atime, mtime)
+}
+
+func (c *CachedFS) Chown(name string, uid, gid int) error {
+	defer c.Invalidate(name)
+	return c.base.Chown(name, uid, gid)
+}
+
+func (c *CachedFS) Truncate(name string, size int64) error {
+	defer c.Invalidate(name)
+	return c.base.Truncate(name, size)
+}
+
+func (c *CachedFS) Chdir(dir string) error { return c.base.Chdir(dir) }
+func (c *CachedFS) Getwd() (string, error) { return c.base.Getwd() }
+func (c *CachedFS) Separator() uint8       { return c.base.Separator() }
+func (c *CachedFS) ListSeparator() uint8   { return c.base.ListSeparator() }
+func (c *CachedFS) TempDir() string        { return c.base.TempDir() }
+
+func (c *CachedFS) Readlink(name string) (string, error) {
+	sl, ok := c.base.(absfs.SymLinker)
+	if !ok {
+		return "", &os.PathError{Op: "readlink", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	return sl.Readlink(name)
+}
+
+func (c *CachedFS) Lchown(name string, uid, gid int) error {
+	sl, ok := c.base.(absfs.SymLinker)
+	if !ok {
+		return &os.PathError{Op: "lchown", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	defer c.Invalidate(name)
+	return sl.Lchown(name, uid, gid)
+}
+
+func (c *CachedFS) Symlink(oldname, newname string) error {
+	sl, ok := c.base.(absfs.SymLinker)
+	if !ok {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: absfs.ErrNotImplemented}
+	}
+	defer c.Invalidate(newname)
+	return sl.Symlink(oldname, newname)
+}
+
+// cachedFile is a file opened for writing through a CachedFS.
+type cachedFile struct {
+	absfs.File
+	c   *CachedFS
+	key string
+}
+
+func (f *cachedFile) Close() error {
+	defer f.c.invalidate(f.key)
+	return f.File.Close()
+}
+
+// cachedDir is a file opened for reading through a CachedFS. If it is a
+// directory, its listing is read whole from the cache or from the file on
+// first use, and served from memory.
+type cachedDir struct {
+	absfs.File
+	c      *CachedFS
+	key    string
+	list   []os.FileInfo
+	read   bool
+	offset int
+}
+
+func (d *cachedDir) load() error {
+	if d.read {
+		return nil
+	}
+	if e, ok := d.c.get(d.c.dirs, d.key); ok {
+		d.list = e.list
+	} else {
+		list, err := d.File.Readdir(-1)
+		if err != nil {
+			return err
+		}
+		d.c.put(d.c.dirs, d.key, cacheEntry{list: list})
+		d.list = list
+	}
+	d.read = true
+	return nil
+}
+
+func (d *cachedDir) Readdir(n int) ([]os.FileInfo, error) {
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+	rest := d.list[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.list)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.offset += n
+	return rest[:n], nil
+}
+
+func (d *cachedDir) Readdirnames(n int) ([]string, error) {
+	infos, err := d.Readdir(n)
+	names := make([]string, len(infos))
+	for i, info := range infos {
+		names[i] = info.Name()
+	}
+	return names, err
+}
+
+// Seek rewinds the listing when seeking to the start, as os.File does for
+// directories.
+func (d *cachedDir) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		d.offset = 0
+	}
+	return d.File.Seek(offset, whence)
+}
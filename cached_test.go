@@ -0,0 +1,147 @@
+package osfs_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/osfs"
+)
+
+func TestCached(t *testing.T) {
+	base, dir := newTempFS(t)
+	if err := base.Mkdir("dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := base.WriteFile("dir/a", []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fs := osfs.Cached(base, time.Hour)
+
+	list := func() string {
+		t.Helper()
+		f, err := fs.Open("dir")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		names, err := f.Readdirnames(-1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Strings(names)
+		return strings.Join(names, ",")
+	}
+	size := func(name string) int64 {
+		t.Helper()
+		info, err := fs.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.Size()
+	}
+
+	if got := size("dir/a"); got != 1 {
+		t.Fatalf("size = %d, want 1", got)
+	}
+	if got := list(); got != "a" {
+		t.Fatalf("dir lists %s, want a", got)
+	}
+
+	// Changes made behind the cache's back are not seen: hits.
+	if err := os.WriteFile(filepath.Join(dir, "dir", "a"), []byte("aaa"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "dir", "b"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := size("dir/a"); got != 1 {
+		t.Errorf("size = %d from the cache, want the stale 1", got)
+	}
+	if got := size(osfs.FromNative(filepath.Join(dir, "dir", "a"))); got != 1 {
+		t.Errorf("size = %d through the absolute path, want the stale 1", got)
+	}
+	if got := list(); got != "a" {
+		t.Errorf("dir lists %s from the cache, want the stale a", got)
+	}
+
+	// Explicit invalidation.
+	fs.Invalidate("dir/a")
+	if got := size("dir/a"); got != 3 {
+		t.Errorf("size = %d after Invalidate, want 3", got)
+	}
+	if got := list(); got != "a,b" {
+		t.Errorf("dir lists %s after invalidating a child, want a,b", got)
+	}
+
+	// Writes through the cache invalidate what they change.
+	f, err := fs.Create("dir/c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("cccc")
+	f.Close()
+	if got := size("dir/c"); got != 4 {
+		t.Errorf("size = %d after writing, want 4", got)
+	}
+	if got := list(); got != "a,b,c" {
+		t.Errorf("dir lists %s after Create, want a,b,c", got)
+	}
+	if err := fs.Rename("dir/c", "dir/d"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.Stat("dir/c"); !os.IsNotExist(err) {
+		t.Errorf("Stat of a renamed file: %v, want not exist", err)
+	}
+	if err := fs.Chmod("dir/a", 0600); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := fs.Stat("dir/a"); err != nil {
+		t.Error(err)
+	} else if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("mode after Chmod = %v, want 0600", info.Mode())
+	}
+	if err := fs.RemoveAll("dir"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.Stat("dir/a"); !os.IsNotExist(err) {
+		t.Errorf("Stat below a removed directory: %v, want not exist", err)
+	}
+	if err := fs.MkdirAll("x/y", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.Stat("x/y"); err != nil {
+		t.Error(err)
+	}
+
+	if _, ok := absfs.FileSystem(fs).(absfs.SymLinker); !ok {
+		t.Error("Cached FileSystem does not implement absfs.SymLinker")
+	}
+}
+
+func TestCachedExpiry(t *testing.T) {
+	base, dir := newTempFS(t)
+	if err := base.WriteFile("file", []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fs := osfs.Cached(base, 10*time.Millisecond)
+	if _, err := fs.Stat("file"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "file"), []byte("aa"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	info, err := fs.Stat("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != 2 {
+		t.Errorf("size = %d after the ttl, want 2", info.Size())
+	}
+}
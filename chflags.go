@@ -0,0 +1,37 @@
+package osfs
+
+// File flags for Chflags and Lchflags. They are the flags common to macOS
+// and the BSDs; not every system supports every flag, and the system
+// ("SF_") flags can only be changed by the superuser.
+const (
+	FlagNoDump          uint32 = 0x00000001 // UF_NODUMP: do not dump the file
+	FlagUserImmutable   uint32 = 0x00000002 // UF_IMMUTABLE, "uchg": the file may not be changed
+	FlagUserAppend      uint32 = 0x00000004 // UF_APPEND, "uappnd": writes may only append
+	FlagOpaque          uint32 = 0x00000008 // UF_OPAQUE: the directory is opaque in union mounts
+	FlagHidden          uint32 = 0x00008000 // UF_HIDDEN: hide the file in GUIs (macOS, FreeBSD)
+	FlagArchived        uint32 = 0x00010000 // SF_ARCHIVED: the file has been archived
+	FlagSystemImmutable uint32 = 0x00020000 // SF_IMMUTABLE, "schg": the file may not be changed
+	FlagSystemAppend    uint32 = 0x00040000 // SF_APPEND, "sappnd": writes may only append
+)
+
+// Chflags sets the file flags of the named file to flags, replacing all
+// current flags and following symbolic links. File flags are available on
+// macOS and the BSDs; elsewhere, and on filesystems without them, an error
+// wrapping ErrNotSupported is returned.
+func (fs *FileSystem) Chflags(name string, flags uint32) error {
+	path, err := fs.fixPathErr("chflags", name)
+	if err != nil {
+		return err
+	}
+	return chflags(path, flags)
+}
+
+// Lchflags is Chflags without following a final symbolic link, so the flags
+// of the link itself are set. It is not supported on OpenBSD.
+func (fs *FileSystem) Lchflags(name string, flags uint32) error {
+	path, err := fs.fixPathErr("lchflags", name)
+	if err != nil {
+		return err
+	}
+	return lchflags(path, flags)
+}
@@ -0,0 +1,36 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+// +build darwin dragonfly freebsd netbsd openbsd
+
+package osfs
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// chflagsError wraps err for op on path, mapping an unsupported filesystem
+// to ErrNotSupported.
+func chflagsError(op, path string, err error) error {
+	if err == unix.EOPNOTSUPP {
+		err = ErrNotSupported
+	}
+	return &os.PathError{Op: op, Path: path, Err: err}
+}
+
+func chflags(path string, flags uint32) error {
+	if err := unix.Chflags(path, int(flags)); err != nil {
+		return chflagsError("chflags", path, err)
+	}
+	return nil
+}
+
+// fileFlags returns the file flags recorded in info by Stat or Lstat.
+func fileFlags(info os.FileInfo) (uint32, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint32(st.Flags), true
+}
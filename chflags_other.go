@@ -0,0 +1,18 @@
+//go:build !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package osfs
+
+import "os"
+
+func chflags(path string, flags uint32) error {
+	return &os.PathError{Op: "chflags", Path: path, Err: ErrNotSupported}
+}
+
+func lchflags(path string, flags uint32) error {
+	return &os.PathError{Op: "lchflags", Path: path, Err: ErrNotSupported}
+}
+
+func fileFlags(info os.FileInfo) (uint32, bool) {
+	return 0, false
+}
@@ -0,0 +1,348 @@
+package osfs
+
+import (
+	"errors"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/absfs/absfs"
+)
+
+// ErrOutsideRoot is returned, wrapped in an *os.PathError, when a path given
+// to a FileSystem returned by Chroot would resolve outside its root.
+var ErrOutsideRoot = errors.New("path escapes root")
+
+// maxSymlinks bounds the symbolic links followed while resolving one path.
+const maxSymlinks = 255
+
+// Chroot returns a FileSystem confined to the directory root of base. Paths
+// passed to it are resolved against root: absolute paths are taken to start
+// at root and relative paths at the confined working directory, which
+// starts out as "/". A path that climbs above root with `..` is rejected
+// with ErrOutsideRoot rather than clamped.
+//
+// If base implements absfs.SymLinker, symbolic links met while resolving a
+// path are followed by Chroot itself and their targets checked, so a link
+// cannot lead out of root either. Absolute link targets are read in the
+// namespace of base. Symlink translates absolute targets into that
+// namespace, so links created through the confined view keep working
+// inside it. Resolution and use of a path are separate steps, so a tree
+// that others modify concurrently can still race a path out of root.
+//
+// Getwd returns confined paths. The returned FileSystem also implements
+// absfs.SymLinker.
+func Chroot(base absfs.FileSystem, root string) (absfs.FileSystem, error) {
+	root = FromNative(root)
+	if !path.IsAbs(root) {
+		wd, err := base.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		root = path.Join(FromNative(wd), root)
+	}
+	root = Clean(root)
+	info, err := base.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, &os.PathError{Op: "chroot", Path: root, Err: errors.New("not a directory")}
+	}
+	c := &chrootFS{base: base, root: root, cwd: "/"}
+	c.sl, _ = base.(absfs.SymLinker)
+	return c, nil
+}
+
+type chrootFS struct {
+	base absfs.FileSystem
+	sl   absfs.SymLinker
+	root string
+	cwd  string
+}
+
+// inside reports whether the base path p lies within the root.
+func (c *chrootFS) inside(p string) bool {
+	return p == c.root || strings.HasPrefix(p, strings.TrimSuffix(c.root, "/")+"/")
+}
+
+// confine cleans name relative to the confined working directory and
+// returns it relative to the root, without a leading slash.
+func (c *chrootFS) confine(op, name string) (string, error) {
+	if !path.IsAbs(name) {
+		name = c.cwd + "/" + name
+	}
+	rel := path.Clean(strings.TrimLeft(name, "/"))
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", &os.PathError{Op: op, Path: name, Err: ErrOutsideRoot}
+	}
+	if rel == "." {
+		rel = ""
+	}
+	return rel, nil
+}
+
+// resolve maps name to a path of base inside the root, following symbolic
+// links in every element but the last, and in the last too if follow is
+// set.
+func (c *chrootFS) resolve(op, name string, follow bool) (string, error) {
+	rel, err := c.confine(op, name)
+	if err != nil {
+		return "", err
+	}
+	if c.sl == nil {
+		return path.Join(c.root, rel), nil
+	}
+
+	var parts []string
+	if rel != "" {
+		parts = strings.Split(rel, "/")
+	}
+	cur := c.root
+	links := 0
+	for len(parts) > 0 {
+		next := path.Join(cur, parts[0])
+		parts = parts[1:]
+		if len(parts) == 0 && !follow {
+			cur = next
+			break
+		}
+		info, err := c.sl.Lstat(next)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			cur = next
+			continue
+		}
+
+		links++
+		if links > maxSymlinks {
+			return "", &os.PathError{Op: op, Path: name, Err: errors.New("too many levels of symbolic links")}
+		}
+		target, err := c.sl.Readlink(next)
+		if err != nil {
+			return "", err
+		}
+		target = FromNative(target)
+		if !path.IsAbs(target) {
+			target = path.Join(cur, target)
+		}
+		target = Clean(target)
+		if !c.inside(target) {
+			return "", &os.PathError{Op: op, Path: name, Err: ErrOutsideRoot}
+		}
+		// Start over from the root with the link target in front of the
+		// elements still to be resolved.
+		var rest []string
+		if t := strings.TrimPrefix(strings.TrimPrefix(target, c.root), "/"); t != "" {
+			rest = strings.Split(t, "/")
+		}
+		parts = append(rest, parts...)
+		cur = c.root
+	}
+	return cur, nil
+}
+
+// outer returns the confined path of the base path p, which must be inside
+// the root.
+func (c *chrootFS) outer(p string) string {
+	return "/" + strings.TrimPrefix(strings.TrimPrefix(p, c.root), "/")
+}
+
+func (c *chrootFS) OpenFile(name string, flag int, perm os.FileMode) (absfs.File, error) {
+	p, err := c.resolve("open", name, true)
+	if err != nil {
+		return nil, err
+	}
+	return c.base.OpenFile(p, flag, perm)
+}
+
+func (c *chrootFS) Open(name string) (absfs.File, error) {
+	return c.OpenFile(name, os.O_RDONLY, 0)
+}
+
+func (c *chrootFS) Create(name string) (absfs.File, error) {
+	return c.OpenFile(name, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0666)
+}
+
+func (c *chrootFS) Mkdir(name string, perm os.FileMode) error {
+	p, err := c.resolve("mkdir", name, true)
+	if err != nil {
+		return err
+	}
+	return c.base.Mkdir(p, perm)
+}
+
+func (c *chrootFS) MkdirAll(name string, perm os.FileMode) error {
+	p, err := c.resolve("mkdir", name, true)
+	if err != nil {
+		return err
+	}
+	return c.base.MkdirAll(p, perm)
+}
+
+func (c *chrootFS) Remove(name string) error {
+	p, err := c.resolve("remove", name, false)
+	if err != nil {
+		return err
+	}
+	return c.base.Remove(p)
+}
+
+func (c *chrootFS) RemoveAll(name string) error {
+	p, err := c.resolve("removeall", name, false)
+	if err != nil {
+		return err
+	}
+	if p == c.root {
+		return &os.PathError{Op: "removeall", Path: name, Err: errors.New("cannot remove the root")}
+	}
+	return c.base.RemoveAll(p)
+}
+
+func (c *chrootFS) Rename(oldpath, newpath string) error {
+	o, err := c.resolve("rename", oldpath, false)
+	if err != nil {
+		return err
+	}
+	n, err := c.resolve("rename", newpath, false)
+	if err != nil {
+		return err
+	}
+	return c.base.Rename(o, n)
+}
+
+func (c *chrootFS) Stat(name string) (os.FileInfo, error) {
+	p, err := c.resolve("stat", name, true)
+	if err != nil {
+		return nil, err
+	}
+	return c.base.Stat(p)
+}
+
+func (c *chrootFS) Chmod(name string, mode os.FileMode) error {
+	p, err := c.resolve("chmod", name, true)
+	if err != nil {
+		return err
+	}
+	return c.base.Chmod(p, mode)
+}
+
+func (c *chrootFS) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	p, err := c.resolve("chtimes", name, true)
+	if err != nil {
+		return err
+	}
+	return c.base.Chtimes(p, atime, mtime)
+}
+
+func (c *chrootFS) Chown(name string, uid, gid int) error {
+	p, err := c.resolve("chown", name, true)
+	if err != nil {
+		return err
+	}
+	return c.base.Chown(p, uid, gid)
+}
+
+func (c *chrootFS) Truncate(name string, size int64) error {
+	p, err := c.resolve("truncate", name, true)
+	if err != nil {
+		return err
+	}
+	return c.base.Truncate(p, size)
+}
+
+func (c *chrootFS) Separator() uint8     { return '/' }
+func (c *chrootFS) ListSeparator() uint8 { return c.base.ListSeparator() }
+
+func (c *chrootFS) Chdir(dir string) error {
+	p, err := c.resolve("chdir", dir, true)
+	if err != nil {
+		return err
+	}
+	info, err := c.base.Stat(p)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return &os.PathError{Op: "chdir", Path: dir, Err: errors.New("not a directory")}
+	}
+	c.cwd = c.outer(p)
+	return nil
+}
+
+func (c *chrootFS) Getwd() (string, error) {
+	return c.cwd, nil
+}
+
+// TempDir returns the temporary directory of base if it lies inside the
+// root, and the root otherwise.
+func (c *chrootFS) TempDir() string {
+	if dir := Clean(FromNative(c.base.TempDir())); c.inside(dir) {
+		return c.outer(dir)
+	}
+	return "/"
+}
+
+func (c *chrootFS) Lstat(name string) (os.FileInfo, error) {
+	if c.sl == nil {
+		return nil, &os.PathError{Op: "lstat", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	p, err := c.resolve("lstat", name, false)
+	if err != nil {
+		return nil, err
+	}
+	return c.sl.Lstat(p)
+}
+
+func (c *chrootFS) Lchown(name string, uid, gid int) error {
+	if c.sl == nil {
+		return &os.PathError{Op: "lchown", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	p, err := c.resolve("lchown", name, false)
+	if err != nil {
+		return err
+	}
+	return c.sl.Lchown(p, uid, gid)
+}
+
+// Readlink returns the target of the named link. Absolute targets inside the
+// root are returned as confined paths.
+func (c *chrootFS) Readlink(name string) (string, error) {
+	if c.sl == nil {
+		return "", &os.PathError{Op: "readlink", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	p, err := c.resolve("readlink", name, false)
+	if err != nil {
+		return "", err
+	}
+	target, err := c.sl.Readlink(p)
+	if err != nil {
+		return "", err
+	}
+	if t := FromNative(target); path.IsAbs(t) && c.inside(Clean(t)) {
+		return c.outer(Clean(t)), nil
+	}
+	return target, nil
+}
+
+// Symlink creates newname as a link to oldname. oldname is a confined path,
+// relative to the directory of newname if not absolute, and is stored as the
+// corresponding absolute path of base.
+func (c *chrootFS) Symlink(oldname, newname string) error {
+	if c.sl == nil {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: absfs.ErrNotImplemented}
+	}
+	n, err := c.resolve("symlink", newname, false)
+	if err != nil {
+		return err
+	}
+	target := oldname
+	if !path.IsAbs(target) {
+		target = c.outer(path.Dir(n)) + "/" + target
+	}
+	rel, err := c.confine("symlink", target)
+	if err != nil {
+		return err
+	}
+	return c.sl.Symlink(path.Join(c.root, rel), n)
+}
@@ -0,0 +1,127 @@
+package osfs_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/osfs"
+)
+
+func TestChroot(t *testing.T) {
+	base, dir := newTempFS(t)
+	if err := base.MkdirAll("root/sub", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := base.WriteFile("secret", []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := base.WriteFile("root/file", []byte("inside"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fs, err := osfs.Chroot(base, "root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sl := fs.(absfs.SymLinker)
+
+	read := func(name string) (string, error) {
+		f, err := fs.Open(name)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		data, err := io.ReadAll(f)
+		return string(data), err
+	}
+
+	for _, name := range []string{"file", "/file", "sub/../file", "./file"} {
+		if data, err := read(name); err != nil || data != "inside" {
+			t.Errorf("reading %q: %q, %v", name, data, err)
+		}
+	}
+
+	escapes := []string{"../secret", "../../etc/passwd", "sub/../../secret", "/../secret"}
+	for _, name := range escapes {
+		if _, err := read(name); !errors.Is(err, osfs.ErrOutsideRoot) {
+			t.Errorf("reading %q: err = %v, want %v", name, err, osfs.ErrOutsideRoot)
+		}
+	}
+
+	// An absolute path of the host names a path inside the root.
+	if _, err := read(filepath.ToSlash(filepath.Join(dir, "secret"))); !os.IsNotExist(err) {
+		t.Errorf("host path: err = %v, want not exist", err)
+	}
+
+	f, err := fs.Create("/new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if !base.Exists("root/new") {
+		t.Error("Create(/new) did not create root/new")
+	}
+
+	if err := fs.Chdir("sub"); err != nil {
+		t.Fatal(err)
+	}
+	if wd, _ := fs.Getwd(); wd != "/sub" {
+		t.Errorf("Getwd = %q, want /sub", wd)
+	}
+	if data, err := read("../file"); err != nil || data != "inside" {
+		t.Errorf("reading ../file from /sub: %q, %v", data, err)
+	}
+	if _, err := read("../../secret"); !errors.Is(err, osfs.ErrOutsideRoot) {
+		t.Errorf("reading ../../secret from /sub: err = %v, want %v", err, osfs.ErrOutsideRoot)
+	}
+	if err := fs.Chdir("/"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := base.Symlink(filepath.Join(dir, "secret"), "root/escape"); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := base.Symlink(dir, "root/sub/up"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"escape", "sub/up/secret", "sub/up/root/file"} {
+		if _, err := read(name); !errors.Is(err, osfs.ErrOutsideRoot) {
+			t.Errorf("reading %q: err = %v, want %v", name, err, osfs.ErrOutsideRoot)
+		}
+	}
+	if _, err := sl.Lstat("escape"); err != nil {
+		t.Errorf("Lstat of an escaping link: %v", err)
+	}
+	if err := fs.Remove("escape"); err != nil {
+		t.Errorf("removing an escaping link: %v", err)
+	}
+	if !base.Exists("secret") {
+		t.Error("removing the link removed its target")
+	}
+
+	if err := sl.Symlink("/file", "sub/abs"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sl.Symlink("../file", "sub/rel"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"sub/abs", "sub/rel"} {
+		if data, err := read(name); err != nil || data != "inside" {
+			t.Errorf("reading through %s: %q, %v", name, data, err)
+		}
+		if target, err := sl.Readlink(name); err != nil || target != "/file" {
+			t.Errorf("Readlink(%q) = %q, %v, want /file", name, target, err)
+		}
+	}
+	if err := sl.Symlink("../../secret", "sub/bad"); !errors.Is(err, osfs.ErrOutsideRoot) {
+		t.Errorf("Symlink out of the root: err = %v, want %v", err, osfs.ErrOutsideRoot)
+	}
+
+	if _, err := osfs.Chroot(base, "secret"); err == nil {
+		t.Error("Chroot to a file succeeded")
+	}
+}
@@ -0,0 +1,181 @@
+package osfs
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/absfs/absfs"
+)
+
+// readChunkSize bounds a single read of ReadFileContext, and so how long a
+// cancelled context can go unnoticed.
+const readChunkSize = 1 << 20
+
+// removeProgressInterval is the number of entries RemoveAllContext removes
+// between calls to its progress function.
+const removeProgressInterval = 256
+
+// OpenFileContext is OpenFile with a context. Opening itself cannot be
+// interrupted, so ctx is checked before the call and again once it returns;
+// if ctx was cancelled in the meantime the file is closed and ctx.Err() is
+// returned.
+func (fs *FileSystem) OpenFileContext(ctx context.Context, name string, flag int, perm os.FileMode) (absfs.File, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f, err := fs.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// ReadFileContext reads the named file like ReadFileInto, checking ctx
+// between reads of at most 1 MiB and returning ctx.Err() once it is done.
+// The buffer is preallocated from the size reported by Stat.
+func (fs *FileSystem) ReadFileContext(ctx context.Context, name string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	path, err := fs.fixPathErr("open", name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	size := 0
+	if info, err := f.Stat(); err == nil {
+		if s := info.Size(); int64(int(s)) == s {
+			size = int(s)
+		}
+	}
+	size++ // one byte for the final read at EOF
+
+	buf := make([]byte, 0, size)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if len(buf) == cap(buf) {
+			buf = append(buf, 0)[:len(buf)]
+		}
+		end := cap(buf)
+		if end-len(buf) > readChunkSize {
+			end = len(buf) + readChunkSize
+		}
+		n, err := f.Read(buf[len(buf):end])
+		buf = buf[:len(buf)+n]
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return buf, err
+		}
+	}
+}
+
+// RemoveAllContext removes name and any children it contains, like RemoveAll,
+// checking ctx before each entry and returning ctx.Err() once it is done.
+// The tree is removed depth first, reading directories in small batches, so
+// a cancelled call leaves a smaller but still well-formed tree behind.
+//
+// If progress is not nil it is called with the running count of removed
+// entries every 256 entries and once more when RemoveAllContext returns.
+// As with RemoveAll, a name that does not exist is not an error, and
+// read-only files are removed on Windows too.
+func (fs *FileSystem) RemoveAllContext(ctx context.Context, name string, progress func(removed int)) error {
+	path, err := fs.fixPathErr("RemoveAll", name)
+	if err != nil {
+		return err
+	}
+	if base := filepath.Base(name); base == "." {
+		return &os.PathError{Op: "RemoveAll", Path: name, Err: os.ErrInvalid}
+	}
+	r := &treeRemover{ctx: ctx, progress: progress}
+	err = r.remove(path)
+	if progress != nil && r.removed != r.reported {
+		progress(r.removed)
+	}
+	return err
+}
+
+// treeRemover holds the state of one RemoveAllContext call.
+type treeRemover struct {
+	ctx      context.Context
+	progress func(int)
+	removed  int
+	reported int
+}
+
+func (r *treeRemover) done() {
+	r.removed++
+	if r.progress != nil && r.removed%removeProgressInterval == 0 {
+		r.progress(r.removed)
+		r.reported = r.removed
+	}
+}
+
+func (r *treeRemover) remove(path string) error {
+	if err := r.ctx.Err(); err != nil {
+		return err
+	}
+	err := os.Remove(path)
+	if err == nil {
+		r.done()
+		return nil
+	}
+	info, lerr := os.Lstat(path)
+	if lerr != nil {
+		if os.IsNotExist(lerr) {
+			return nil
+		}
+		return lerr
+	}
+	if !info.IsDir() {
+		return err
+	}
+
+	for {
+		// Reopen the directory for every batch, as removing entries while
+		// reading can make the read position skip some.
+		f, err := os.Open(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		names, err := f.Readdirnames(rangeDirBatch)
+		f.Close()
+		for _, name := range names {
+			if err := r.remove(filepath.Join(path, name)); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF || len(names) < rangeDirBatch {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := r.ctx.Err(); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	r.done()
+	return nil
+}
@@ -0,0 +1,128 @@
+package osfs
+
+import (
+	"errors"
+	"os"
+)
+
+// CopyFile copies the contents of the file src to dst and returns the number
+// of bytes copied. dst is created or truncated and ends up with the
+// permission bits of src. Where the platform offers an in-kernel copy it is
+// used, so on Linux the data is moved with copy_file_range(2), which lets
+// filesystems that support it share extents instead of duplicating them.
+// On macOS a dst that does not exist yet is made a clone of src with
+// fclonefileat(2) where the filesystem supports it, as APFS does; a clone
+// also takes the timestamps and extended attributes of src. Elsewhere the
+// data is copied through a pooled buffer. Copying a file onto itself
+// is an error.
+func (fs *FileSystem) CopyFile(dst, src string) (int64, error) {
+	dstpath, err := fs.fixPathErr("copy", dst)
+	if err != nil {
+		return 0, err
+	}
+	srcpath, err := fs.fixPathErr("copy", src)
+	if err != nil {
+		return 0, err
+	}
+	return fs.copyFile(dstpath, srcpath)
+}
+
+// copyFile implements CopyFile for native paths.
+func (fs *FileSystem) copyFile(dst, src string) (int64, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if dinfo, err := os.Stat(dst); err == nil && os.SameFile(info, dinfo) {
+		return 0, &os.PathError{Op: "copy", Path: dst, Err: errors.New("source and destination are the same file")}
+	}
+	cloned, err := cloneFile(dst, in)
+	if err != nil {
+		return 0, err
+	}
+	if cloned {
+		return info.Size(), os.Chmod(dst, info.Mode().Perm())
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return 0, err
+	}
+	n, err := fs.copyContents(out, in, info.Size())
+	if err1 := out.Close(); err1 != nil && err == nil {
+		err = err1
+	}
+	if err == nil {
+		err = os.Chmod(dst, info.Mode().Perm())
+	}
+	return n, err
+}
+
+// moveFile renames the native file src to dst, falling back to a copy
+// followed by removal of src when the two are on different devices.
+func (fs *FileSystem) moveFile(dst, src string) error {
+	err := os.Rename(src, dst)
+	if err == nil || !isCrossDevice(err) {
+		return err
+	}
+	if _, err := fs.copyFile(dst, src); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// Move renames oldpath to newpath like Rename. When the two are on different
+// devices, where a rename is impossible, Move copies oldpath to newpath
+// instead, recursing into directories and keeping modes, times and symbolic
+// links, and then removes oldpath. As with a rename, an existing file at
+// newpath is replaced; an existing directory is not, and moving a directory
+// across devices onto it fails.
+//
+// The fallback is not atomic. If the copy fails, newpath may be left partly
+// written while oldpath is untouched.
+func (fs *FileSystem) Move(oldpath, newpath string) error {
+	src, err := fs.fixPathErr("move", oldpath)
+	if err != nil {
+		return err
+	}
+	dst, err := fs.fixPathErr("move", newpath)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(src, dst)
+	if err == nil {
+		fs.temps.remove(src)
+		return nil
+	}
+	if !isCrossDevice(err) {
+		return err
+	}
+
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		if _, err := os.Lstat(dst); err == nil {
+			return &os.LinkError{Op: "move", Old: src, New: dst, Err: os.ErrExist}
+		}
+	}
+	c := &treeCopier{
+		fs:   fs,
+		opts: CopyOptions{PreserveSymlinks: true, PreserveTimes: true, Overwrite: true},
+		root: dst,
+	}
+	if err := c.copy(dst, src, info, nil); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(src); err != nil {
+		return err
+	}
+	fs.temps.remove(src)
+	return nil
+}
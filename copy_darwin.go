@@ -0,0 +1,28 @@
+package osfs
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// copyContents copies src to dst through a pooled buffer.
+func (fs *FileSystem) copyContents(dst, src *os.File, size int64) (int64, error) {
+	return fs.copyBuffer(dst, src)
+}
+
+// cloneFile clones src to the new file dst with fclonefileat(2), so that
+// the two share their data until either is changed. It reports false,
+// leaving the copy to copyContents, when dst exists or the filesystem
+// cannot clone, as with HFS+ or a dst on another volume.
+func cloneFile(dst string, src *os.File) (bool, error) {
+	err := unix.Fclonefileat(int(src.Fd()), unix.AT_FDCWD, dst, unix.CLONE_NOOWNERCOPY)
+	switch err {
+	case nil:
+		return true, nil
+	case unix.EEXIST, unix.ENOTSUP, unix.EXDEV, unix.EINVAL:
+		// EINVAL comes from systems that predate CLONE_NOOWNERCOPY.
+		return false, nil
+	}
+	return false, &os.PathError{Op: "fclonefileat", Path: dst, Err: err}
+}
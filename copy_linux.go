@@ -0,0 +1,41 @@
+package osfs
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// copyContents copies src to dst with copy_file_range(2), falling back to a
+// buffered copy when the kernel or the filesystems involved do not support
+// it.
+func (fs *FileSystem) copyContents(dst, src *os.File, size int64) (int64, error) {
+	var written int64
+	for {
+		n, err := unix.CopyFileRange(int(src.Fd()), nil, int(dst.Fd()), nil, 1<<30, 0)
+		if err != nil {
+			if written == 0 {
+				switch err {
+				case unix.ENOSYS, unix.EXDEV, unix.EINVAL, unix.EOPNOTSUPP, unix.EPERM:
+					return fs.copyBuffer(dst, src)
+				}
+			}
+			return written, os.NewSyscallError("copy_file_range", err)
+		}
+		if n == 0 {
+			// Some pseudo filesystems report files as empty to
+			// copy_file_range; read them the ordinary way.
+			if written == 0 && size > 0 {
+				return fs.copyBuffer(dst, src)
+			}
+			return written, nil
+		}
+		written += int64(n)
+	}
+}
+
+// cloneFile reports that files are not cloned; copy_file_range(2) already
+// lets filesystems share extents.
+func cloneFile(dst string, src *os.File) (bool, error) {
+	return false, nil
+}
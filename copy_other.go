@@ -0,0 +1,18 @@
+//go:build !darwin && !linux
+// +build !darwin,!linux
+
+package osfs
+
+import (
+	"os"
+)
+
+// copyContents copies src to dst through a pooled buffer.
+func (fs *FileSystem) copyContents(dst, src *os.File, size int64) (int64, error) {
+	return fs.copyBuffer(dst, src)
+}
+
+// cloneFile reports that files cannot be cloned on this platform.
+func cloneFile(dst string, src *os.File) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,116 @@
+package osfs
+
+import "os"
+
+// MetaFields is a bit mask selecting the metadata copied by CopyMetadata.
+type MetaFields uint
+
+const (
+	MetaMode   MetaFields = 1 << iota // permission and special mode bits
+	MetaTimes                         // access and modification times
+	MetaOwner                         // user and group ids
+	MetaXattrs                        // extended attributes
+	MetaFlags                         // file flags, as set by Chflags
+
+	MetaAll = MetaMode | MetaTimes | MetaOwner | MetaXattrs | MetaFlags
+)
+
+// CopyMetadata copies the metadata selected by what from src to dst without
+// touching the contents of dst. It is typically used as a final pass after
+// the data of a file has been copied. Symbolic links are followed.
+//
+// Every selected field is attempted even if an earlier one fails; the
+// failures are returned together as a MultiError. Fields that cannot be
+// represented on the current platform fail with ErrNotSupported.
+//
+// Extended attributes of src are added to dst, replacing those of the same
+// name; attributes that only dst has are kept. Unprivileged callers may be
+// unable to set attributes outside the "user" namespace on Linux. Flags are
+// applied last, as flags such as FlagUserImmutable prevent the other
+// changes.
+func (fs *FileSystem) CopyMetadata(src, dst string, what MetaFields) error {
+	src, err := fs.fixPathErr("copy", src)
+	if err != nil {
+		return err
+	}
+	dst, err = fs.fixPathErr("copy", dst)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	var errs MultiError
+
+	// Changing the owner may clear the setuid and setgid bits, so it has to
+	// happen before the mode is applied.
+	if what&MetaOwner != 0 {
+		if uid, gid, ok := FileOwner(info); ok {
+			if err := os.Chown(dst, uid, gid); err != nil {
+				errs = append(errs, err)
+			}
+		} else {
+			errs = append(errs, &os.PathError{Op: "chown", Path: dst, Err: ErrNotSupported})
+		}
+	}
+
+	// Setting extended attributes may need write permission, which the new
+	// mode can take away.
+	if what&MetaXattrs != 0 {
+		errs = append(errs, copyXattrs(src, dst)...)
+	}
+
+	if what&MetaMode != 0 {
+		if err := os.Chmod(dst, info.Mode()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Times go last, as the other changes may update them.
+	if what&MetaTimes != 0 {
+		atime, mtime, _, ok := FileTimes(info)
+		if !ok {
+			atime, mtime = info.ModTime(), info.ModTime()
+		}
+		if err := os.Chtimes(dst, atime, mtime); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if what&MetaFlags != 0 {
+		if flags, ok := fileFlags(info); ok {
+			if err := chflags(dst, flags); err != nil {
+				errs = append(errs, err)
+			}
+		} else {
+			errs = append(errs, &os.PathError{Op: "chflags", Path: dst, Err: ErrNotSupported})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// copyXattrs copies every extended attribute of src to dst, returning the
+// failures.
+func copyXattrs(src, dst string) []error {
+	attrs, err := listXattr(src)
+	if err != nil {
+		return []error{err}
+	}
+	var errs []error
+	for _, attr := range attrs {
+		data, err := getXattr(src, attr)
+		if err == nil {
+			err = setXattr(dst, attr, data)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
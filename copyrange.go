@@ -0,0 +1,42 @@
+package osfs
+
+import (
+	"io"
+	"os"
+)
+
+// CopyRangeTo copies length bytes starting at srcOffset in f to dst starting
+// at dstOffset, and returns the number of bytes copied. It uses pread and
+// pwrite style I/O, so the current offsets of f and dst are left as they
+// were. Fewer than length bytes are copied, without error, only when f ends
+// before srcOffset+length.
+//
+// On Linux the data is moved with copy_file_range(2), which lets the kernel,
+// and filesystems that support it, share or copy the blocks without passing
+// them through user space. Elsewhere, and when the kernel refuses, for
+// example across filesystems, the range is copied through a buffer from the
+// FileSystem's pool.
+func (f *File) CopyRangeTo(dst *File, srcOffset, dstOffset, length int64) (int64, error) {
+	if srcOffset < 0 || dstOffset < 0 || length < 0 {
+		return 0, &os.PathError{Op: "copyrange", Path: f.f.Name(), Err: os.ErrInvalid}
+	}
+	return f.filer.copyRange(dst.f, f.f, srcOffset, dstOffset, length)
+}
+
+// copyRangeBuffer copies length bytes from src at srcOffset to dst at
+// dstOffset through a pooled buffer, without moving either file offset.
+func (fs *FileSystem) copyRangeBuffer(dst, src *os.File, srcOffset, dstOffset, length int64) (int64, error) {
+	return fs.copyBuffer(&offsetWriter{dst, dstOffset}, io.NewSectionReader(src, srcOffset, length))
+}
+
+// offsetWriter writes sequentially to w starting at off, using WriteAt.
+type offsetWriter struct {
+	w   io.WriterAt
+	off int64
+}
+
+func (w *offsetWriter) Write(p []byte) (int, error) {
+	n, err := w.w.WriteAt(p, w.off)
+	w.off += int64(n)
+	return n, err
+}
@@ -0,0 +1,38 @@
+package osfs
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// copyRange copies a range of src to dst with copy_file_range(2), falling
+// back to a buffered copy for whatever the kernel will not copy.
+func (fs *FileSystem) copyRange(dst, src *os.File, srcOffset, dstOffset, length int64) (int64, error) {
+	var written int64
+	for written < length {
+		chunk := length - written
+		if chunk > 1<<30 {
+			chunk = 1 << 30
+		}
+		roff, woff := srcOffset+written, dstOffset+written
+		n, err := unix.CopyFileRange(int(src.Fd()), &roff, int(dst.Fd()), &woff, int(chunk), 0)
+		if err != nil {
+			switch err {
+			case unix.ENOSYS, unix.EXDEV, unix.EINVAL, unix.EOPNOTSUPP, unix.EPERM:
+				n, err := fs.copyRangeBuffer(dst, src, roff, woff, length-written)
+				return written + n, err
+			}
+			return written, os.NewSyscallError("copy_file_range", err)
+		}
+		if n == 0 {
+			// End of src, or a pseudo filesystem that reports its files
+			// as empty to copy_file_range; a buffered read tells them
+			// apart.
+			n, err := fs.copyRangeBuffer(dst, src, roff, woff, length-written)
+			return written + n, err
+		}
+		written += int64(n)
+	}
+	return written, nil
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package osfs
+
+import "os"
+
+// copyRange copies a range of src to dst through a pooled buffer.
+func (fs *FileSystem) copyRange(dst, src *os.File, srcOffset, dstOffset, length int64) (int64, error) {
+	return fs.copyRangeBuffer(dst, src, srcOffset, dstOffset, length)
+}
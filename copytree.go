@@ -0,0 +1,230 @@
+package osfs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// CopyOptions configures CopyTree.
+type CopyOptions struct {
+	// PreserveSymlinks recreates symbolic links in the copy with the same
+	// target instead of copying what they point to.
+	PreserveSymlinks bool
+
+	// PreserveTimes copies the access and modification times of files,
+	// directories and, where the platform allows, preserved symbolic links.
+	PreserveTimes bool
+
+	// Overwrite replaces files and symbolic links already present in the
+	// destination. Without it an existing file is an error. Existing
+	// directories are always merged into, and never replaced by a file.
+	Overwrite bool
+
+	// ContinueOnError carries on past entries that cannot be copied and
+	// returns their errors together as a MultiError once the whole tree has
+	// been visited.
+	ContinueOnError bool
+}
+
+// CopyTree copies the file or directory tree src to dst. Directories are
+// recreated with the mode of their source and regular files are copied as
+// by CopyFile. Symbolic links are followed unless opts.PreserveSymlinks is
+// set, and a link leading back to one of its own parent directories is
+// reported as an error rather than followed forever. Other special files,
+// such as devices and sockets, are skipped. If dst lies inside src it is
+// left out of the copy.
+//
+// Without opts.ContinueOnError CopyTree stops at the first error, leaving
+// a partial copy behind.
+func (fs *FileSystem) CopyTree(dst, src string, opts CopyOptions) error {
+	src, err := fs.fixPathErr("copy", src)
+	if err != nil {
+		return err
+	}
+	dst, err = fs.fixPathErr("copy", dst)
+	if err != nil {
+		return err
+	}
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	c := &treeCopier{fs: fs, opts: opts, root: dst}
+	if err := c.copy(dst, src, info, nil); err != nil {
+		return err
+	}
+	if len(c.errs) > 0 {
+		return c.errs
+	}
+	return nil
+}
+
+// treeCopier holds the state of one CopyTree call.
+type treeCopier struct {
+	fs   *FileSystem
+	opts CopyOptions
+	root string
+	errs MultiError
+}
+
+// fail records err and returns nil when continuing on errors, and returns
+// err otherwise.
+func (c *treeCopier) fail(err error) error {
+	if err == nil || !c.opts.ContinueOnError {
+		return err
+	}
+	c.errs = append(c.errs, err)
+	return nil
+}
+
+// copy copies the native path src, described by info, to dst. parents
+// describes the source directories above src.
+func (c *treeCopier) copy(dst, src string, info os.FileInfo, parents []os.FileInfo) error {
+	if info.Mode()&os.ModeSymlink != 0 {
+		if c.opts.PreserveSymlinks {
+			return c.fail(c.symlink(dst, src))
+		}
+		target, err := os.Stat(src)
+		if err != nil {
+			return c.fail(err)
+		}
+		info = target
+	}
+
+	switch {
+	case info.IsDir():
+		return c.dir(dst, src, info, parents)
+	case info.Mode().IsRegular():
+		return c.fail(c.file(dst, src, info))
+	}
+	return nil
+}
+
+func (c *treeCopier) dir(dst, src string, info os.FileInfo, parents []os.FileInfo) error {
+	for _, p := range parents {
+		if os.SameFile(p, info) {
+			return c.fail(&os.PathError{Op: "copytree", Path: src, Err: errors.New("directory cycle")})
+		}
+	}
+
+	exists, err := c.clear(dst, true)
+	if err != nil {
+		return c.fail(err)
+	}
+	if !exists {
+		// Keep the new directory writable until its contents are in place.
+		if err := os.Mkdir(dst, 0700); err != nil {
+			return c.fail(err)
+		}
+	}
+
+	f, err := os.Open(src)
+	if err != nil {
+		return c.fail(err)
+	}
+	entries, err := f.ReadDir(-1)
+	f.Close()
+	if err := c.fail(err); err != nil {
+		return err
+	}
+
+	parents = append(parents, info)
+	for _, e := range entries {
+		s := filepath.Join(src, e.Name())
+		if s == c.root {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil {
+			if err := c.fail(err); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := c.copy(filepath.Join(dst, e.Name()), s, fi, parents); err != nil {
+			return err
+		}
+	}
+
+	if err := c.fail(os.Chmod(dst, info.Mode())); err != nil {
+		return err
+	}
+	if c.opts.PreserveTimes {
+		return c.fail(c.times(dst, info))
+	}
+	return nil
+}
+
+func (c *treeCopier) file(dst, src string, info os.FileInfo) error {
+	if _, err := c.clear(dst, false); err != nil {
+		return err
+	}
+	if _, err := c.fs.copyFile(dst, src); err != nil {
+		return err
+	}
+	if c.opts.PreserveTimes {
+		return c.times(dst, info)
+	}
+	return nil
+}
+
+func (c *treeCopier) symlink(dst, src string) error {
+	target, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+	if _, err := c.clear(dst, false); err != nil {
+		return err
+	}
+	if err := c.fs.symlink(target, dst); err != nil {
+		return err
+	}
+	if !c.opts.PreserveTimes {
+		return nil
+	}
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	atime, mtime, _, ok := FileTimes(info)
+	if !ok {
+		atime, mtime = info.ModTime(), info.ModTime()
+	}
+	if err := c.fs.Lchtimes(FromNative(dst), atime, mtime); err != nil && !errors.Is(err, ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+func (c *treeCopier) times(dst string, info os.FileInfo) error {
+	atime, mtime, _, ok := FileTimes(info)
+	if !ok {
+		atime, mtime = info.ModTime(), info.ModTime()
+	}
+	return os.Chtimes(dst, atime, mtime)
+}
+
+// clear makes way for a new entry at dst. An existing directory is kept,
+// and exists reported, when a directory is wanted. Anything else in the way
+// is an error unless Overwrite is set, in which case it is removed.
+func (c *treeCopier) clear(dst string, dir bool) (exists bool, err error) {
+	info, err := os.Lstat(dst)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if dir && info.IsDir() {
+		return true, nil
+	}
+	if info.IsDir() {
+		return false, &os.PathError{Op: "copytree", Path: dst, Err: errors.New("is a directory")}
+	}
+	if !c.opts.Overwrite {
+		return false, &os.PathError{Op: "copytree", Path: dst, Err: os.ErrExist}
+	}
+	return false, os.Remove(dst)
+}
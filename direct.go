@@ -0,0 +1,22 @@
+package osfs
+
+import "unsafe"
+
+// DirectIOAlignment is the alignment of the buffers returned by
+// AlignedBuffer. It satisfies the memory, offset and length alignment that
+// direct I/O requires on common disks and filesystems: 512-byte sectors and
+// 4 KiB pages alike.
+const DirectIOAlignment = 4096
+
+// AlignedBuffer returns a zeroed slice of length and capacity size whose
+// first byte is aligned to DirectIOAlignment, for use with files opened with
+// O_DIRECT. Reads and writes on such files must also use offsets and
+// lengths that are multiples of the alignment.
+func AlignedBuffer(size int) []byte {
+	buf := make([]byte, size+DirectIOAlignment)
+	off := 0
+	if r := int(uintptr(unsafe.Pointer(&buf[0])) & (DirectIOAlignment - 1)); r != 0 {
+		off = DirectIOAlignment - r
+	}
+	return buf[off : off+size : off+size]
+}
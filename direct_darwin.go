@@ -0,0 +1,25 @@
+package osfs
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// O_DIRECT may be added to the flag of OpenFile to bypass the page cache.
+// On macOS it is not an open flag; OpenFile sets F_NOCACHE on the file
+// instead. See the documentation of O_DIRECT on Linux for the other
+// platforms.
+const O_DIRECT = 0x10000000
+
+func openFile(path string, flag int, perm os.FileMode) (*os.File, error) {
+	f, err := os.OpenFile(path, flag&^O_DIRECT, perm)
+	if err != nil || flag&O_DIRECT == 0 {
+		return f, err
+	}
+	if _, err := unix.FcntlInt(f.Fd(), unix.F_NOCACHE, 1); err != nil {
+		f.Close()
+		return nil, &os.PathError{Op: "fcntl", Path: path, Err: err}
+	}
+	return f, nil
+}
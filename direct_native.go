@@ -0,0 +1,23 @@
+//go:build aix || dragonfly || freebsd || linux || netbsd || solaris
+// +build aix dragonfly freebsd linux netbsd solaris
+
+package osfs
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// O_DIRECT may be added to the flag of OpenFile to bypass the page cache.
+// It is the system's O_DIRECT on Linux, the BSDs, Solaris and AIX, sets
+// F_NOCACHE on macOS and FILE_FLAG_NO_BUFFERING on Windows, and makes
+// OpenFile fail with ErrNotSupported elsewhere. Reads and writes on the
+// file must then use buffers, offsets and lengths aligned as described at
+// AlignedBuffer, except on macOS, where unaligned I/O is merely slower.
+// Some filesystems, such as tmpfs on Linux, refuse O_DIRECT.
+const O_DIRECT = unix.O_DIRECT
+
+func openFile(path string, flag int, perm os.FileMode) (*os.File, error) {
+	return os.OpenFile(path, flag, perm)
+}
@@ -0,0 +1,19 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !solaris && !windows
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!solaris,!windows
+
+package osfs
+
+import "os"
+
+// O_DIRECT may be added to the flag of OpenFile to bypass the page cache.
+// It is not supported on this platform, and OpenFile fails with
+// ErrNotSupported when it is given. See the documentation of O_DIRECT on
+// Linux for the other platforms.
+const O_DIRECT = 0x10000000
+
+func openFile(path string, flag int, perm os.FileMode) (*os.File, error) {
+	if flag&O_DIRECT != 0 {
+		return nil, &os.PathError{Op: "open", Path: path, Err: ErrNotSupported}
+	}
+	return os.OpenFile(path, flag, perm)
+}
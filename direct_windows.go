@@ -0,0 +1,62 @@
+package osfs
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// O_DIRECT may be added to the flag of OpenFile to bypass the page cache.
+// On Windows the file is opened with FILE_FLAG_NO_BUFFERING. See the
+// documentation of O_DIRECT on Linux for the other platforms.
+const O_DIRECT = windows.FILE_FLAG_NO_BUFFERING
+
+func openFile(path string, flag int, perm os.FileMode) (*os.File, error) {
+	if flag&O_DIRECT == 0 {
+		return os.OpenFile(path, flag, perm)
+	}
+
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: path, Err: err}
+	}
+
+	var access uint32
+	switch flag & (os.O_RDONLY | os.O_WRONLY | os.O_RDWR) {
+	case os.O_RDONLY:
+		access = windows.GENERIC_READ
+	case os.O_WRONLY:
+		access = windows.GENERIC_WRITE
+	case os.O_RDWR:
+		access = windows.GENERIC_READ | windows.GENERIC_WRITE
+	}
+	if flag&os.O_APPEND != 0 {
+		access &^= windows.GENERIC_WRITE
+		access |= windows.FILE_APPEND_DATA
+	}
+
+	var create uint32
+	switch {
+	case flag&(os.O_CREATE|os.O_EXCL) == os.O_CREATE|os.O_EXCL:
+		create = windows.CREATE_NEW
+	case flag&(os.O_CREATE|os.O_TRUNC) == os.O_CREATE|os.O_TRUNC:
+		create = windows.CREATE_ALWAYS
+	case flag&os.O_CREATE == os.O_CREATE:
+		create = windows.OPEN_ALWAYS
+	case flag&os.O_TRUNC == os.O_TRUNC:
+		create = windows.TRUNCATE_EXISTING
+	default:
+		create = windows.OPEN_EXISTING
+	}
+
+	attrs := uint32(windows.FILE_ATTRIBUTE_NORMAL | windows.FILE_FLAG_NO_BUFFERING)
+	if flag&os.O_CREATE != 0 && perm&0200 == 0 {
+		attrs = windows.FILE_ATTRIBUTE_READONLY | windows.FILE_FLAG_NO_BUFFERING
+	}
+	share := uint32(windows.FILE_SHARE_READ | windows.FILE_SHARE_WRITE | windows.FILE_SHARE_DELETE)
+	h, err := windows.CreateFile(p, access, share, nil, create, attrs, 0)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: path, Err: err}
+	}
+	return os.NewFile(uintptr(h), path), nil
+}
@@ -0,0 +1,215 @@
+package osfs
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/absfs/absfs"
+)
+
+// DryRun returns a FileSystem that performs reads on base but only reports
+// the operations that would modify it: instead of creating, removing or
+// renaming entries, changing attributes, truncating, creating symbolic links
+// or opening files for writing, it calls log with the name of the operation,
+// as used by Instrumented, and its path, and reports success. For rename
+// the path is "oldpath -> newpath" and for symlink "newname -> oldname".
+//
+// Files opened for writing, including by Create, are stand-ins that never
+// touch base: writes are accepted and discarded without being logged, reads
+// find nothing, and Stat describes an empty file grown by the writes made
+// through it, even where base has a file of that name. Because nothing
+// changes, later reads do not see earlier pretend changes: a file "created"
+// cannot be opened for reading, and a "removed" one is still there. The
+// returned FileSystem also implements absfs.SymLinker; if base does not,
+// Lstat and Readlink fail with absfs.ErrNotImplemented.
+func DryRun(base absfs.FileSystem, log func(op, path string)) absfs.FileSystem {
+	return &dryRunFS{base: base, log: log}
+}
+
+type dryRunFS struct {
+	base absfs.FileSystem
+	log  func(op, path string)
+}
+
+func (d *dryRunFS) OpenFile(name string, flag int, perm os.FileMode) (absfs.File, error) {
+	if flag&writeFlags == 0 {
+		return d.base.OpenFile(name, flag, perm)
+	}
+	d.log("open", name)
+	return &dryRunFile{d: d, name: name, mode: perm}, nil
+}
+
+func (d *dryRunFS) Open(name string) (absfs.File, error) {
+	return d.base.Open(name)
+}
+
+func (d *dryRunFS) Create(name string) (absfs.File, error) {
+	d.log("create", name)
+	return &dryRunFile{d: d, name: name, mode: 0666}, nil
+}
+
+func (d *dryRunFS) Mkdir(name string, perm os.FileMode) error {
+	d.log("mkdir", name)
+	return nil
+}
+
+func (d *dryRunFS) MkdirAll(name string, perm os.FileMode) error {
+	d.log("mkdirall", name)
+	return nil
+}
+
+func (d *dryRunFS) Remove(name string) error {
+	d.log("remove", name)
+	return nil
+}
+
+func (d *dryRunFS) RemoveAll(name string) error {
+	d.log("removeall", name)
+	return nil
+}
+
+func (d *dryRunFS) Rename(oldpath, newpath string) error {
+	d.log("rename", oldpath+" -> "+newpath)
+	return nil
+}
+
+func (d *dryRunFS) Chmod(name string, mode os.FileMode) error {
+	d.log("chmod", name)
+	return nil
+}
+
+func (d *dryRunFS) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	d.log("chtimes", name)
+	return nil
+}
+
+func (d *dryRunFS) Chown(name string, uid, gid int) error {
+	d.log("chown", name)
+	return nil
+}
+
+func (d *dryRunFS) Truncate(name string, size int64) error {
+	d.log("truncate", name)
+	return nil
+}
+
+func (d *dryRunFS) Stat(name string) (os.FileInfo, error) { return d.base.Stat(name) }
+func (d *dryRunFS) Chdir(dir string) error                { return d.base.Chdir(dir) }
+func (d *dryRunFS) Getwd() (string, error)                { return d.base.Getwd() }
+func (d *dryRunFS) Separator() uint8                      { return d.base.Separator() }
+func (d *dryRunFS) ListSeparator() uint8                  { return d.base.ListSeparator() }
+func (d *dryRunFS) TempDir() string                       { return d.base.TempDir() }
+
+func (d *dryRunFS) Lstat(name string) (os.FileInfo, error) {
+	sl, ok := d.base.(absfs.SymLinker)
+	if !ok {
+		return nil, &os.PathError{Op: "lstat", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	return sl.Lstat(name)
+}
+
+func (d *dryRunFS) Readlink(name string) (string, error) {
+	sl, ok := d.base.(absfs.SymLinker)
+	if !ok {
+		return "", &os.PathError{Op: "readlink", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	return sl.Readlink(name)
+}
+
+func (d *dryRunFS) Lchown(name string, uid, gid int) error {
+	d.log("lchown", name)
+	return nil
+}
+
+func (d *dryRunFS) Symlink(oldname, newname string) error {
+	d.log("symlink", newname+" -> "+oldname)
+	return nil
+}
+
+// dryRunFile stands in for a file opened for writing through a dryRunFS.
+type dryRunFile struct {
+	d    *dryRunFS
+	name string
+	mode os.FileMode
+	size int64
+	off  int64
+}
+
+func (f *dryRunFile) Name() string { return f.name }
+func (f *dryRunFile) Close() error { return nil }
+func (f *dryRunFile) Sync() error  { return nil }
+
+func (f *dryRunFile) Read(b []byte) (int, error) {
+	return 0, io.EOF
+}
+
+func (f *dryRunFile) ReadAt(b []byte, off int64) (int, error) {
+	return 0, io.EOF
+}
+
+func (f *dryRunFile) Write(b []byte) (int, error) {
+	n, err := f.WriteAt(b, f.off)
+	f.off += int64(n)
+	return n, err
+}
+
+func (f *dryRunFile) WriteAt(b []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, &os.PathError{Op: "writeat", Path: f.name, Err: errors.New("negative offset")}
+	}
+	if end := off + int64(len(b)); end > f.size {
+		f.size = end
+	}
+	return len(b), nil
+}
+
+func (f *dryRunFile) WriteString(s string) (int, error) {
+	return f.Write([]byte(s))
+}
+
+func (f *dryRunFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.off
+	case io.SeekEnd:
+		offset += f.size
+	}
+	if offset < 0 {
+		return 0, &os.PathError{Op: "seek", Path: f.name, Err: os.ErrInvalid}
+	}
+	f.off = offset
+	return offset, nil
+}
+
+func (f *dryRunFile) Truncate(size int64) error {
+	f.d.log("truncate", f.name)
+	f.size = size
+	return nil
+}
+
+func (f *dryRunFile) Stat() (os.FileInfo, error) {
+	return dryRunInfo{f}, nil
+}
+
+func (f *dryRunFile) Readdir(n int) ([]os.FileInfo, error) {
+	return nil, &os.PathError{Op: "readdir", Path: f.name, Err: errors.New("not a directory")}
+}
+
+func (f *dryRunFile) Readdirnames(n int) ([]string, error) {
+	return nil, &os.PathError{Op: "readdirnames", Path: f.name, Err: errors.New("not a directory")}
+}
+
+// dryRunInfo describes a dryRunFile.
+type dryRunInfo struct {
+	f *dryRunFile
+}
+
+func (i dryRunInfo) Name() string       { return path.Base(FromNative(i.f.name)) }
+func (i dryRunInfo) Size() int64        { return i.f.size }
+func (i dryRunInfo) Mode() os.FileMode  { return i.f.mode.Perm() }
+func (i dryRunInfo) ModTime() time.Time { return time.Time{} }
+func (i dryRunInfo) IsDir() bool        { return false }
+func (i dryRunInfo) Sys() interface{}   { return nil }
@@ -0,0 +1,117 @@
+package osfs_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/osfs"
+)
+
+func TestDryRun(t *testing.T) {
+	base, dir := newTempFS(t)
+	if err := base.Mkdir("dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := base.WriteFile("dir/file", []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := func() string {
+		t.Helper()
+		var list []string
+		err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			list = append(list, p+" "+info.Mode().String()+" "+info.ModTime().String())
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "dir", "file"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Strings(list)
+		return strings.Join(list, "\n") + "\n" + string(data)
+	}
+	before := snapshot()
+
+	var logged []string
+	fs := osfs.DryRun(base, func(op, path string) {
+		logged = append(logged, op+" "+path)
+	})
+	sl := fs.(absfs.SymLinker)
+
+	// Reads go through.
+	f, err := fs.Open("dir/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(f)
+	f.Close()
+	if err != nil || string(data) != "data" {
+		t.Errorf("reading through the dry run: %q, %v", data, err)
+	}
+
+	c, err := fs.Create("dir/new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := c.WriteString("hello"); n != 5 || err != nil {
+		t.Errorf("WriteString = %d, %v", n, err)
+	}
+	if info, err := c.Stat(); err != nil || info.Size() != 5 || info.Name() != "new" {
+		t.Errorf("Stat of the stand-in file = %v, %v", info, err)
+	}
+	c.Close()
+	w, err := fs.OpenFile("dir/file", os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("overwritten"))
+	w.Close()
+
+	now := time.Now()
+	for _, err := range []error{
+		fs.Mkdir("dir/sub", 0755),
+		fs.MkdirAll("a/b", 0755),
+		fs.Remove("dir/file"),
+		fs.RemoveAll("dir"),
+		fs.Rename("dir/file", "moved"),
+		fs.Chmod("dir/file", 0600),
+		fs.Chtimes("dir/file", now, now),
+		fs.Truncate("dir/file", 0),
+		sl.Symlink("dir/file", "link"),
+	} {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+
+	want := []string{
+		"create dir/new",
+		"open dir/file",
+		"mkdir dir/sub",
+		"mkdirall a/b",
+		"remove dir/file",
+		"removeall dir",
+		"rename dir/file -> moved",
+		"chmod dir/file",
+		"chtimes dir/file",
+		"truncate dir/file",
+		"symlink link -> dir/file",
+	}
+	if got := strings.Join(logged, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("logged\n%s\nwant\n%s", got, strings.Join(want, "\n"))
+	}
+	if after := snapshot(); after != before {
+		t.Errorf("the dry run changed the filesystem:\n%s\nwas\n%s", after, before)
+	}
+}
@@ -0,0 +1,21 @@
+package osfs
+
+import "strings"
+
+// MultiError collects the errors of an operation that carries on past
+// individual failures instead of stopping at the first one.
+type MultiError []error
+
+func (e MultiError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the collected errors so that errors.Is and errors.As can
+// match any of them.
+func (e MultiError) Unwrap() []error {
+	return e
+}
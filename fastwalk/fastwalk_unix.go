@@ -28,7 +28,7 @@ func readDir(dirName string, fn func(dirName, entName string, typ os.FileMode) e
 	// 	annouce = true
 	// }
 
-	fd, err := syscall.Open(dirName, 0, 0)
+	fd, err := openDir(dirName)
 	if err != nil {
 		if err.Error() == "permission denied" {
 			return nil
@@ -45,7 +45,7 @@ func readDir(dirName string, fn func(dirName, entName string, typ os.FileMode) e
 	for {
 		if bufp >= nbuf {
 			bufp = 0
-			nbuf, err = syscall.ReadDirent(fd, buf)
+			nbuf, err = readDirent(fd, buf)
 			if err != nil {
 				return os.NewSyscallError("readdirent", err)
 			}
@@ -78,6 +78,27 @@ func readDir(dirName string, fn func(dirName, entName string, typ os.FileMode) e
 	}
 }
 
+// openDir opens dirName for reading, retrying when interrupted by a signal.
+func openDir(dirName string) (int, error) {
+	for {
+		fd, err := syscall.Open(dirName, 0, 0)
+		if err != syscall.EINTR {
+			return fd, err
+		}
+	}
+}
+
+// readDirent reads directory entries from fd into buf, retrying when
+// interrupted by a signal, such as the preemption signals of the runtime.
+func readDirent(fd int, buf []byte) (int, error) {
+	for {
+		n, err := syscall.ReadDirent(fd, buf)
+		if err != syscall.EINTR {
+			return n, err
+		}
+	}
+}
+
 func parseDirEnt(buf []byte) (consumed int, name string, typ os.FileMode) {
 	// golang.org/issue/15653
 	dirent := (*syscall.Dirent)(unsafe.Pointer(&buf[0]))
@@ -115,8 +136,15 @@ func parseDirEnt(buf []byte) (consumed int, name string, typ os.FileMode) {
 		return
 	}
 
-	nameBuf := (*[unsafe.Sizeof(dirent.Name)]byte)(unsafe.Pointer(&dirent.Name[0]))
-	nameLen := bytes.IndexByte(nameBuf[:], 0)
+	// The name runs from its offset to the end of the record, which may be
+	// longer than the Name array on filesystems allowing names over 255
+	// bytes. Only scan the record itself for the terminating 0.
+	nameOff := int(unsafe.Offsetof(dirent.Name))
+	if consumed < nameOff {
+		panic(fmt.Sprintf("record length %d < name offset %d", consumed, nameOff))
+	}
+	nameBuf := buf[nameOff:consumed]
+	nameLen := bytes.IndexByte(nameBuf, 0)
 	if nameLen < 0 {
 		panic("failed to find terminating 0 byte in dirent")
 	}
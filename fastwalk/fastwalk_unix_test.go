@@ -0,0 +1,111 @@
+//go:build linux && !appengine
+// +build linux,!appengine
+
+package fastwalk
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"unsafe"
+)
+
+// dirent builds a linux_dirent64 record for name, padded to 8 bytes.
+func dirent(ino uint64, typ uint8, name string) []byte {
+	var d syscall.Dirent
+	off := int(unsafe.Offsetof(d.Name))
+	reclen := (off + len(name) + 1 + 7) &^ 7
+	buf := make([]byte, reclen)
+	*(*uint64)(unsafe.Pointer(&buf[unsafe.Offsetof(d.Ino)])) = ino
+	*(*uint16)(unsafe.Pointer(&buf[unsafe.Offsetof(d.Reclen)])) = uint16(reclen)
+	buf[unsafe.Offsetof(d.Type)] = typ
+	copy(buf[off:], name)
+	return buf
+}
+
+func TestParseDirEnt(t *testing.T) {
+	long := strings.Repeat("x", 300)
+	tests := []struct {
+		name string
+		typ  uint8
+		mode os.FileMode
+	}{
+		{"a", syscall.DT_REG, 0},
+		{".", syscall.DT_DIR, os.ModeDir},
+		{"link", syscall.DT_LNK, os.ModeSymlink},
+		{long, syscall.DT_REG, 0},
+		{strings.Repeat("y", 255), syscall.DT_DIR, os.ModeDir},
+	}
+
+	var buf []byte
+	for _, test := range tests {
+		buf = append(buf, dirent(1, test.typ, test.name)...)
+	}
+	// Keep the records at the start of a larger buffer filled with
+	// non-zero bytes, so a scan past the record would run into garbage.
+	stream := append(append([]byte(nil), buf...), strings.Repeat("z", 512)...)
+	stream = stream[:len(buf)]
+
+	for _, test := range tests {
+		consumed, name, typ := parseDirEnt(stream)
+		if name != test.name {
+			t.Errorf("parsed name of %d bytes %.10q..., want %d bytes", len(name), name, len(test.name))
+		}
+		if typ != test.mode {
+			t.Errorf("%.10q: type %v, want %v", test.name, typ, test.mode)
+		}
+		stream = stream[consumed:]
+	}
+	if len(stream) != 0 {
+		t.Errorf("%d bytes left over", len(stream))
+	}
+
+	consumed, name, _ := parseDirEnt(dirent(0, syscall.DT_REG, "gone"))
+	if name != "" || consumed == 0 {
+		t.Errorf("deleted entry parsed as %q, consumed %d", name, consumed)
+	}
+}
+
+func TestReadDirSignals(t *testing.T) {
+	dir := t.TempDir()
+	const n = 2000
+	for i := 0; i < n; i++ {
+		f, err := os.Create(filepath.Join(dir, strconv.Itoa(i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+
+	// SIGURG is what the runtime uses for preemption and is otherwise
+	// ignored, so flooding the process with it is harmless.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				syscall.Kill(os.Getpid(), syscall.SIGURG)
+			}
+		}
+	}()
+
+	for i := 0; i < 20; i++ {
+		count := 0
+		err := readDir(dir, func(dirName, entName string, typ os.FileMode) error {
+			count++
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != n {
+			t.Fatalf("readDir saw %d entries, want %d", count, n)
+		}
+	}
+}
@@ -0,0 +1,354 @@
+package osfs
+
+import (
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/absfs/absfs"
+)
+
+// FaultRule describes faults that a FileSystem returned by Faulty injects
+// into the calls it matches.
+type FaultRule struct {
+	// Ops are the operations the rule applies to, named as by
+	// Instrumented, such as "open", "write" or "rename". An empty list
+	// matches every operation.
+	Ops []string
+
+	// Path is a pattern, in the syntax of Match, for the path of the
+	// operation as passed in, or the name of the File for calls on opened
+	// Files. An empty pattern matches every path.
+	Path string
+
+	// After is the number of matching calls that are let through
+	// untouched before the rule starts to fire.
+	After int
+
+	// Probability, if between 0 and 1, is the chance that the rule fires
+	// for a matching call; otherwise it fires for every one.
+	Probability float64
+
+	// Latency is a delay added to the calls the rule fires for.
+	Latency time.Duration
+
+	// Err, if not nil, is returned by the calls the rule fires for, wrapped
+	// in an *os.PathError, without calling the underlying FileSystem. A
+	// syscall.Errno such as syscall.EACCES or syscall.ENOSPC makes the
+	// error behave like the real one for os.IsPermission and errors.Is.
+	Err error
+}
+
+// FaultRules are the rules of a FileSystem returned by Faulty.
+type FaultRules []FaultRule
+
+// Faulty returns a FileSystem that forwards every call to base except where
+// rules say otherwise, for testing how code handles failing or slow file
+// systems. For every call the rules are tried in order: each rule that
+// fires delays the call by its Latency, and the first one with an Err
+// fails the call with it. Getwd and Close are never faulted, so opened
+// Files can always be released. The returned FileSystem is safe for concurrent
+// use if base is, and also implements absfs.SymLinker; if base does not,
+// its symlink methods fail with absfs.ErrNotImplemented.
+func Faulty(base absfs.FileSystem, rules FaultRules) absfs.FileSystem {
+	return &faultyFS{base: base, rules: rules, calls: make([]int, len(rules))}
+}
+
+type faultyFS struct {
+	base  absfs.FileSystem
+	rules FaultRules
+
+	mu    sync.Mutex
+	calls []int // matching calls seen by each rule
+}
+
+// fault applies the rules to a call of op on path, sleeping for the
+// latency of the rules that fire and returning the error to fail the call
+// with, if any.
+func (x *faultyFS) fault(op, path string) error {
+	var delay time.Duration
+	var err error
+	x.mu.Lock()
+	for i, r := range x.rules {
+		if !r.matches(op, path) {
+			continue
+		}
+		x.calls[i]++
+		if x.calls[i] <= r.After {
+			continue
+		}
+		if r.Probability > 0 && r.Probability < 1 && rand.Float64() >= r.Probability {
+			continue
+		}
+		delay += r.Latency
+		if r.Err != nil {
+			err = &os.PathError{Op: op, Path: path, Err: r.Err}
+			break
+		}
+	}
+	x.mu.Unlock()
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	return err
+}
+
+func (r *FaultRule) matches(op, path string) bool {
+	if len(r.Ops) > 0 {
+		found := false
+		for _, o := range r.Ops {
+			if o == op {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if r.Path == "" {
+		return true
+	}
+	ok, _ := Match(r.Path, path)
+	return ok
+}
+
+// file wraps a File returned by base.
+func (x *faultyFS) file(f absfs.File, err error) (absfs.File, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &faultyFile{File: f, x: x}, nil
+}
+
+func (x *faultyFS) OpenFile(name string, flag int, perm os.FileMode) (absfs.File, error) {
+	if err := x.fault("open", name); err != nil {
+		return nil, err
+	}
+	return x.file(x.base.OpenFile(name, flag, perm))
+}
+
+func (x *faultyFS) Open(name string) (absfs.File, error) {
+	if err := x.fault("open", name); err != nil {
+		return nil, err
+	}
+	return x.file(x.base.Open(name))
+}
+
+func (x *faultyFS) Create(name string) (absfs.File, error) {
+	if err := x.fault("create", name); err != nil {
+		return nil, err
+	}
+	return x.file(x.base.Create(name))
+}
+
+func (x *faultyFS) Mkdir(name string, perm os.FileMode) error {
+	if err := x.fault("mkdir", name); err != nil {
+		return err
+	}
+	return x.base.Mkdir(name, perm)
+}
+
+func (x *faultyFS) MkdirAll(name string, perm os.FileMode) error {
+	if err := x.fault("mkdirall", name); err != nil {
+		return err
+	}
+	return x.base.MkdirAll(name, perm)
+}
+
+func (x *faultyFS) Remove(name string) error {
+	if err := x.fault("remove", name); err != nil {
+		return err
+	}
+	return x.base.Remove(name)
+}
+
+func (x *faultyFS) RemoveAll(name string) error {
+	if err := x.fault("removeall", name); err != nil {
+		return err
+	}
+	return x.base.RemoveAll(name)
+}
+
+func (x *faultyFS) Rename(oldpath, newpath string) error {
+	if err := x.fault("rename", oldpath); err != nil {
+		return err
+	}
+	return x.base.Rename(oldpath, newpath)
+}
+
+func (x *faultyFS) Stat(name string) (os.FileInfo, error) {
+	if err := x.fault("stat", name); err != nil {
+		return nil, err
+	}
+	return x.base.Stat(name)
+}
+
+func (x *faultyFS) Chmod(name string, mode os.FileMode) error {
+	if err := x.fault("chmod", name); err != nil {
+		return err
+	}
+	return x.base.Chmod(name, mode)
+}
+
+func (x *faultyFS) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	if err := x.fault("chtimes", name); err != nil {
+		return err
+	}
+	return x.base.Chtimes(name, atime, mtime)
+}
+
+func (x *faultyFS) Chown(name string, uid, gid int) error {
+	if err := x.fault("chown", name); err != nil {
+		return err
+	}
+	return x.base.Chown(name, uid, gid)
+}
+
+func (x *faultyFS) Truncate(name string, size int64) error {
+	if err := x.fault("truncate", name); err != nil {
+		return err
+	}
+	return x.base.Truncate(name, size)
+}
+
+func (x *faultyFS) Chdir(dir string) error {
+	if err := x.fault("chdir", dir); err != nil {
+		return err
+	}
+	return x.base.Chdir(dir)
+}
+
+func (x *faultyFS) Getwd() (string, error) { return x.base.Getwd() }
+func (x *faultyFS) Separator() uint8       { return x.base.Separator() }
+func (x *faultyFS) ListSeparator() uint8   { return x.base.ListSeparator() }
+func (x *faultyFS) TempDir() string        { return x.base.TempDir() }
+
+func (x *faultyFS) Lstat(name string) (os.FileInfo, error) {
+	sl, ok := x.base.(absfs.SymLinker)
+	if !ok {
+		return nil, &os.PathError{Op: "lstat", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	if err := x.fault("lstat", name); err != nil {
+		return nil, err
+	}
+	return sl.Lstat(name)
+}
+
+func (x *faultyFS) Lchown(name string, uid, gid int) error {
+	sl, ok := x.base.(absfs.SymLinker)
+	if !ok {
+		return &os.PathError{Op: "lchown", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	if err := x.fault("lchown", name); err != nil {
+		return err
+	}
+	return sl.Lchown(name, uid, gid)
+}
+
+func (x *faultyFS) Readlink(name string) (string, error) {
+	sl, ok := x.base.(absfs.SymLinker)
+	if !ok {
+		return "", &os.PathError{Op: "readlink", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	if err := x.fault("readlink", name); err != nil {
+		return "", err
+	}
+	return sl.Readlink(name)
+}
+
+func (x *faultyFS) Symlink(oldname, newname string) error {
+	sl, ok := x.base.(absfs.SymLinker)
+	if !ok {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: absfs.ErrNotImplemented}
+	}
+	if err := x.fault("symlink", oldname); err != nil {
+		return err
+	}
+	return sl.Symlink(oldname, newname)
+}
+
+// faultyFile applies the rules of a faultyFS to the calls on a File opened
+// through it.
+type faultyFile struct {
+	absfs.File
+	x *faultyFS
+}
+
+func (f *faultyFile) Read(b []byte) (int, error) {
+	if err := f.x.fault("read", f.Name()); err != nil {
+		return 0, err
+	}
+	return f.File.Read(b)
+}
+
+func (f *faultyFile) ReadAt(b []byte, off int64) (int, error) {
+	if err := f.x.fault("readat", f.Name()); err != nil {
+		return 0, err
+	}
+	return f.File.ReadAt(b, off)
+}
+
+func (f *faultyFile) Write(b []byte) (int, error) {
+	if err := f.x.fault("write", f.Name()); err != nil {
+		return 0, err
+	}
+	return f.File.Write(b)
+}
+
+func (f *faultyFile) WriteAt(b []byte, off int64) (int, error) {
+	if err := f.x.fault("writeat", f.Name()); err != nil {
+		return 0, err
+	}
+	return f.File.WriteAt(b, off)
+}
+
+func (f *faultyFile) WriteString(s string) (int, error) {
+	if err := f.x.fault("write", f.Name()); err != nil {
+		return 0, err
+	}
+	return f.File.WriteString(s)
+}
+
+func (f *faultyFile) Seek(offset int64, whence int) (int64, error) {
+	if err := f.x.fault("seek", f.Name()); err != nil {
+		return 0, err
+	}
+	return f.File.Seek(offset, whence)
+}
+
+func (f *faultyFile) Readdir(n int) ([]os.FileInfo, error) {
+	if err := f.x.fault("readdir", f.Name()); err != nil {
+		return nil, err
+	}
+	return f.File.Readdir(n)
+}
+
+func (f *faultyFile) Readdirnames(n int) ([]string, error) {
+	if err := f.x.fault("readdirnames", f.Name()); err != nil {
+		return nil, err
+	}
+	return f.File.Readdirnames(n)
+}
+
+func (f *faultyFile) Stat() (os.FileInfo, error) {
+	if err := f.x.fault("fstat", f.Name()); err != nil {
+		return nil, err
+	}
+	return f.File.Stat()
+}
+
+func (f *faultyFile) Truncate(size int64) error {
+	if err := f.x.fault("ftruncate", f.Name()); err != nil {
+		return err
+	}
+	return f.File.Truncate(size)
+}
+
+func (f *faultyFile) Sync() error {
+	if err := f.x.fault("sync", f.Name()); err != nil {
+		return err
+	}
+	return f.File.Sync()
+}
@@ -0,0 +1,66 @@
+package osfs_test
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/absfs/osfs"
+)
+
+func TestFaulty(t *testing.T) {
+	base, _ := newTempFS(t)
+	for _, name := range []string{"secret.key", "public.txt"} {
+		if err := base.WriteFile(name, []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fs := osfs.Faulty(base, osfs.FaultRules{
+		{Ops: []string{"open"}, Path: "*.key", Err: syscall.EACCES},
+		{Ops: []string{"write"}, After: 2, Err: syscall.ENOSPC},
+	})
+
+	_, err := fs.Open("secret.key")
+	if !os.IsPermission(err) {
+		t.Errorf("Open(secret.key) error = %v, want a permission error", err)
+	}
+	var perr *os.PathError
+	if !errors.As(err, &perr) || perr.Op != "open" || perr.Path != "secret.key" {
+		t.Errorf("Open(secret.key) error = %#v, want an open PathError for the path", err)
+	}
+	if _, err := fs.Stat("secret.key"); err != nil {
+		t.Errorf("Stat(secret.key), not matched by the rule: %v", err)
+	}
+
+	f, err := fs.OpenFile("public.txt", os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("Open(public.txt), not matched by the rule: %v", err)
+	}
+	defer f.Close()
+	for i := 0; i < 3; i++ {
+		_, err := f.Write([]byte("x"))
+		if i < 2 && err != nil {
+			t.Errorf("write %d: %v, want success before the fault", i, err)
+		}
+		if i == 2 && !errors.Is(err, syscall.ENOSPC) {
+			t.Errorf("write %d: %v, want ENOSPC", i, err)
+		}
+	}
+}
+
+func TestFaultyLatency(t *testing.T) {
+	base, _ := newTempFS(t)
+	const delay = 20 * time.Millisecond
+	fs := osfs.Faulty(base, osfs.FaultRules{{Ops: []string{"mkdir"}, Latency: delay}})
+
+	start := time.Now()
+	if err := fs.Mkdir("dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < delay {
+		t.Errorf("Mkdir took %v, want at least %v", d, delay)
+	}
+}
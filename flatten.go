@@ -0,0 +1,123 @@
+package osfs
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ConflictPolicy selects what Flatten does when a file of the same name is
+// already present in the destination.
+type ConflictPolicy int
+
+const (
+	// ConflictRename appends a numeric suffix to the base name, turning
+	// "photo.jpg" into "photo_1.jpg", "photo_2.jpg" and so on.
+	ConflictRename ConflictPolicy = iota
+
+	// ConflictSkip leaves the existing file alone and does not transfer the
+	// new one.
+	ConflictSkip
+
+	// ConflictOverwrite replaces the existing file.
+	ConflictOverwrite
+)
+
+// FlattenOptions configures FlattenWithOptions.
+type FlattenOptions struct {
+	// Conflict selects how name collisions in the destination are resolved.
+	Conflict ConflictPolicy
+
+	// Move moves files out of the source tree instead of copying them. The
+	// directories of the source tree are left in place.
+	Move bool
+
+	// EncodePath names each file after its path relative to the source
+	// root, with "/" replaced by "_", so "a/b/c.txt" becomes "a_b_c.txt".
+	EncodePath bool
+}
+
+// Flatten copies every regular file in the tree rooted at src directly into
+// the directory dst, resolving name collisions according to conflict. It is
+// FlattenWithOptions with only Conflict set.
+func (fs *FileSystem) Flatten(src, dst string, conflict ConflictPolicy) error {
+	return fs.FlattenWithOptions(src, dst, FlattenOptions{Conflict: conflict})
+}
+
+// FlattenWithOptions copies or moves every regular file in the tree rooted
+// at src into the directory dst, which is created if needed. Symbolic links
+// and other special files are skipped, as is dst itself if it lies inside
+// src. If dst is src the tree is flattened in place: the files already at
+// its top level stay as they are and the rest join them. Flatten stops at
+// the first error.
+func (fs *FileSystem) FlattenWithOptions(src, dst string, opts FlattenOptions) error {
+	src, err := fs.fixPathErr("flatten", src)
+	if err != nil {
+		return err
+	}
+	dst, err = fs.fixPathErr("flatten", dst)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dst, 0777); err != nil {
+		return err
+	}
+
+	return filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path == dst && path != src {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		// Flattening in place leaves the files already in dst alone.
+		if dst == src && filepath.Dir(path) == dst {
+			return nil
+		}
+
+		name := d.Name()
+		if opts.EncodePath {
+			rel, err := filepath.Rel(src, path)
+			if err != nil {
+				return err
+			}
+			name = strings.ReplaceAll(filepath.ToSlash(rel), "/", "_")
+		}
+
+		target := filepath.Join(dst, name)
+		if _, err := os.Lstat(target); err == nil {
+			switch opts.Conflict {
+			case ConflictSkip:
+				return nil
+			case ConflictRename:
+				target = uniqueName(target)
+			}
+		}
+
+		if opts.Move {
+			return fs.moveFile(target, path)
+		}
+		_, err = fs.copyFile(target, path)
+		return err
+	})
+}
+
+// uniqueName returns the first of name_1.ext, name_2.ext, ... that does not
+// exist.
+func uniqueName(path string) string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for i := 1; ; i++ {
+		candidate := base + "_" + strconv.Itoa(i) + ext
+		if _, err := os.Lstat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris && !windows
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris,!windows
+
+package osfs
+
+import "os"
+
+// lockFile is not supported on this platform.
+func lockFile(f *os.File, exclusive, wait bool) error {
+	return &os.PathError{Op: "lock", Path: f.Name(), Err: ErrNotSupported}
+}
+
+// unlockFile is not supported on this platform.
+func unlockFile(f *os.File) error {
+	return &os.PathError{Op: "unlock", Path: f.Name(), Err: ErrNotSupported}
+}
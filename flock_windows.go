@@ -0,0 +1,39 @@
+package osfs
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockFile locks the whole of f with LockFileEx, shared or exclusive. If
+// wait is set it blocks until the lock is granted, otherwise it fails with
+// ErrLocked if another holds a conflicting lock. The lock is released when f
+// is closed.
+func lockFile(f *os.File, exclusive, wait bool) error {
+	var flags uint32
+	if exclusive {
+		flags |= windows.LOCKFILE_EXCLUSIVE_LOCK
+	}
+	if !wait {
+		flags |= windows.LOCKFILE_FAIL_IMMEDIATELY
+	}
+	ol := new(windows.Overlapped)
+	err := windows.LockFileEx(windows.Handle(f.Fd()), flags, 0, ^uint32(0), ^uint32(0), ol)
+	if err == windows.ERROR_LOCK_VIOLATION {
+		err = ErrLocked
+	}
+	if err != nil {
+		return &os.PathError{Op: "lockfileex", Path: f.Name(), Err: err}
+	}
+	return nil
+}
+
+// unlockFile releases a lock placed by lockFile.
+func unlockFile(f *os.File) error {
+	ol := new(windows.Overlapped)
+	if err := windows.UnlockFileEx(windows.Handle(f.Fd()), 0, ^uint32(0), ^uint32(0), ol); err != nil {
+		return &os.PathError{Op: "unlockfileex", Path: f.Name(), Err: err}
+	}
+	return nil
+}
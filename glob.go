@@ -0,0 +1,100 @@
+package osfs
+
+import (
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Glob returns the names of all files matching the absfs pattern, or nil if
+// there is no matching file. The pattern syntax is that of path.Match, so `/`
+// is the only separator on every platform. A leading drive or UNC volume,
+// such as `/c/Users/*/Documents`, is matched literally. Matches are returned
+// sorted, in the same relative or absolute form as pattern.
+//
+// Glob ignores I/O errors such as unreadable directories. The only possible
+// returned errors are path.ErrBadPattern and, for a pattern that cannot
+// name any file, one wrapping ErrInvalidPath.
+func (fs *FileSystem) Glob(pattern string) ([]string, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	if _, err := fs.fixPathErr("glob", pattern); err != nil {
+		return nil, err
+	}
+
+	matches, err := fs.glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+func (fs *FileSystem) glob(pattern string) ([]string, error) {
+	if !hasMeta(pattern) {
+		if _, err := os.Lstat(fs.fixPath(pattern)); err != nil {
+			return nil, nil
+		}
+		return []string{pattern}, nil
+	}
+
+	dir, file := splitGlob(pattern)
+	dirs := []string{dir}
+	if hasMeta(dir) {
+		var err error
+		dirs, err = fs.glob(dir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var matches []string
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(fs.fixPath(dir))
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			ok, err := path.Match(file, e.Name())
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				matches = append(matches, joinGlob(dir, e.Name()))
+			}
+		}
+	}
+	return matches, nil
+}
+
+// splitGlob splits pattern into its directory and final element. The
+// directory has no trailing slash unless it is a root, and is "." when
+// pattern has no directory part.
+func splitGlob(pattern string) (dir, file string) {
+	i := strings.LastIndexByte(pattern, '/')
+	dir, file = pattern[:i+1], pattern[i+1:]
+	switch {
+	case dir == "":
+		dir = "."
+	case dir == "/" || len(dir) == volumeNameLen(dir)+1:
+	default:
+		dir = dir[:len(dir)-1]
+	}
+	return dir, file
+}
+
+func joinGlob(dir, name string) string {
+	switch {
+	case dir == ".":
+		return name
+	case strings.HasSuffix(dir, "/"):
+		return dir + name
+	}
+	return dir + "/" + name
+}
+
+func hasMeta(p string) bool {
+	return strings.ContainsAny(p, `*?[\`)
+}
@@ -0,0 +1,52 @@
+package osfs_test
+
+import (
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/absfs/osfs"
+)
+
+func TestGlob(t *testing.T) {
+	fs, dir := newTempFS(t)
+	wd := osfs.FromNative(dir)
+
+	for _, name := range []string{"a/x.txt", "a/y.go", "b/x.txt", "b/c/x.txt", "top.txt"} {
+		if err := fs.MkdirAll(path.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := fs.WriteFile(name, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		pattern string
+		matches []string
+	}{
+		{"*.txt", []string{"top.txt"}},
+		{"*/x.txt", []string{"a/x.txt", "b/x.txt"}},
+		{"?/*", []string{"a/x.txt", "a/y.go", "b/c", "b/x.txt"}},
+		{"[ab]/*.go", []string{"a/y.go"}},
+		{"*/*/x.txt", []string{"b/c/x.txt"}},
+		{"top.txt", []string{"top.txt"}},
+		{"missing", nil},
+		{"*/missing", nil},
+		{wd + "/*/x.txt", []string{wd + "/a/x.txt", wd + "/b/x.txt"}},
+	}
+	for _, test := range tests {
+		matches, err := fs.Glob(test.pattern)
+		if err != nil {
+			t.Errorf("Glob(%q): %v", test.pattern, err)
+			continue
+		}
+		if strings.Join(matches, ",") != strings.Join(test.matches, ",") {
+			t.Errorf("Glob(%q) = %q, want %q", test.pattern, matches, test.matches)
+		}
+	}
+
+	if _, err := fs.Glob("[a"); err != path.ErrBadPattern {
+		t.Errorf("Glob(%q) error = %v, want %v", "[a", err, path.ErrBadPattern)
+	}
+}
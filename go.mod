@@ -7,4 +7,6 @@ require (
 	github.com/absfs/fstesting v0.0.0-20180810212821-8b575cdeb80d
 	github.com/fatih/color v1.12.0 // indirect
 	github.com/xtgo/set v1.0.0 // indirect
+	golang.org/x/sys v0.10.0
+	golang.org/x/text v0.13.0
 )
@@ -0,0 +1,57 @@
+package osfs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// FindHardlinks walks the tree rooted at root and returns the sorted absfs
+// paths of every regular file that is the same file as target, including
+// target itself if it lies under root. Directories and symbolic links are
+// never candidates, so only regular files are stat'ed.
+func (fs *FileSystem) FindHardlinks(root, target string) ([]string, error) {
+	rootPath, err := fs.fixPathErr("findhardlinks", root)
+	if err != nil {
+		return nil, err
+	}
+	targetPath, err := fs.fixPathErr("findhardlinks", target)
+	if err != nil {
+		return nil, err
+	}
+	targetInfo, err := os.Stat(targetPath)
+	if err != nil {
+		return nil, err
+	}
+	if !targetInfo.Mode().IsRegular() {
+		return nil, &os.PathError{Op: "findhardlinks", Path: targetPath, Err: errors.New("not a regular file")}
+	}
+
+	var links []string
+	err = filepath.WalkDir(rootPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if os.SameFile(targetInfo, info) {
+			links = append(links, FromNative(path))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(links)
+	return links, nil
+}
@@ -0,0 +1,243 @@
+package osfs
+
+import (
+	"os"
+	"time"
+
+	"github.com/absfs/absfs"
+)
+
+// Hooks are callbacks run around every operation of a FileSystem returned
+// by WithHooks. Either may be nil. They are called from the goroutine making
+// the call and must be safe for concurrent use if the FileSystem is used
+// concurrently.
+type Hooks struct {
+	// Before is called before each operation with its name and path.
+	Before func(op, path string)
+
+	// After is called when the operation returns, including when it fails,
+	// with the same name and path and the error returned, which is io.EOF
+	// at the end of a file or directory.
+	After func(op, path string, err error)
+}
+
+// begin calls Before and returns a function, to be deferred with the
+// address of the error result, that calls After.
+func (h *Hooks) begin(op, path string) func(*error) {
+	if h.Before != nil {
+		h.Before(op, path)
+	}
+	return func(errp *error) {
+		if h.After != nil {
+			h.After(op, path, *errp)
+		}
+	}
+}
+
+// WithHooks returns a FileSystem that forwards every call to base and runs
+// the callbacks of h around it, for example to log operations or to start
+// and end tracing spans. Operations are named as by Instrumented. The path
+// is the name passed in; for Rename and Symlink it is the first argument,
+// oldpath or oldname. Calls on opened Files report the name of the File.
+// Separator, ListSeparator and TempDir, which cannot fail, are not hooked.
+//
+// The returned FileSystem also implements absfs.SymLinker; if base does
+// not, its symlink methods fail with absfs.ErrNotImplemented.
+func WithHooks(base absfs.FileSystem, h Hooks) absfs.FileSystem {
+	return &hookedFS{base: base, h: &h}
+}
+
+type hookedFS struct {
+	base absfs.FileSystem
+	h    *Hooks
+}
+
+// file wraps a File returned by base.
+func (k *hookedFS) file(f absfs.File, err error) (absfs.File, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &hookedFile{File: f, h: k.h}, nil
+}
+
+func (k *hookedFS) OpenFile(name string, flag int, perm os.FileMode) (f absfs.File, err error) {
+	defer k.h.begin("open", name)(&err)
+	return k.file(k.base.OpenFile(name, flag, perm))
+}
+
+func (k *hookedFS) Open(name string) (f absfs.File, err error) {
+	defer k.h.begin("open", name)(&err)
+	return k.file(k.base.Open(name))
+}
+
+func (k *hookedFS) Create(name string) (f absfs.File, err error) {
+	defer k.h.begin("create", name)(&err)
+	return k.file(k.base.Create(name))
+}
+
+func (k *hookedFS) Mkdir(name string, perm os.FileMode) (err error) {
+	defer k.h.begin("mkdir", name)(&err)
+	return k.base.Mkdir(name, perm)
+}
+
+func (k *hookedFS) MkdirAll(name string, perm os.FileMode) (err error) {
+	defer k.h.begin("mkdirall", name)(&err)
+	return k.base.MkdirAll(name, perm)
+}
+
+func (k *hookedFS) Remove(name string) (err error) {
+	defer k.h.begin("remove", name)(&err)
+	return k.base.Remove(name)
+}
+
+func (k *hookedFS) RemoveAll(name string) (err error) {
+	defer k.h.begin("removeall", name)(&err)
+	return k.base.RemoveAll(name)
+}
+
+func (k *hookedFS) Rename(oldpath, newpath string) (err error) {
+	defer k.h.begin("rename", oldpath)(&err)
+	return k.base.Rename(oldpath, newpath)
+}
+
+func (k *hookedFS) Stat(name string) (info os.FileInfo, err error) {
+	defer k.h.begin("stat", name)(&err)
+	return k.base.Stat(name)
+}
+
+func (k *hookedFS) Chmod(name string, mode os.FileMode) (err error) {
+	defer k.h.begin("chmod", name)(&err)
+	return k.base.Chmod(name, mode)
+}
+
+func (k *hookedFS) Chtimes(name string, atime time.Time, mtime time.Time) (err error) {
+	defer k.h.begin("chtimes", name)(&err)
+	return k.base.Chtimes(name, atime, mtime)
+}
+
+func (k *hookedFS) Chown(name string, uid, gid int) (err error) {
+	defer k.h.begin("chown", name)(&err)
+	return k.base.Chown(name, uid, gid)
+}
+
+func (k *hookedFS) Truncate(name string, size int64) (err error) {
+	defer k.h.begin("truncate", name)(&err)
+	return k.base.Truncate(name, size)
+}
+
+func (k *hookedFS) Chdir(dir string) (err error) {
+	defer k.h.begin("chdir", dir)(&err)
+	return k.base.Chdir(dir)
+}
+
+func (k *hookedFS) Getwd() (dir string, err error) {
+	defer k.h.begin("getwd", "")(&err)
+	return k.base.Getwd()
+}
+
+func (k *hookedFS) Separator() uint8     { return k.base.Separator() }
+func (k *hookedFS) ListSeparator() uint8 { return k.base.ListSeparator() }
+func (k *hookedFS) TempDir() string      { return k.base.TempDir() }
+
+func (k *hookedFS) Lstat(name string) (info os.FileInfo, err error) {
+	defer k.h.begin("lstat", name)(&err)
+	sl, ok := k.base.(absfs.SymLinker)
+	if !ok {
+		return nil, &os.PathError{Op: "lstat", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	return sl.Lstat(name)
+}
+
+func (k *hookedFS) Lchown(name string, uid, gid int) (err error) {
+	defer k.h.begin("lchown", name)(&err)
+	sl, ok := k.base.(absfs.SymLinker)
+	if !ok {
+		return &os.PathError{Op: "lchown", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	return sl.Lchown(name, uid, gid)
+}
+
+func (k *hookedFS) Readlink(name string) (target string, err error) {
+	defer k.h.begin("readlink", name)(&err)
+	sl, ok := k.base.(absfs.SymLinker)
+	if !ok {
+		return "", &os.PathError{Op: "readlink", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	return sl.Readlink(name)
+}
+
+func (k *hookedFS) Symlink(oldname, newname string) (err error) {
+	defer k.h.begin("symlink", oldname)(&err)
+	sl, ok := k.base.(absfs.SymLinker)
+	if !ok {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: absfs.ErrNotImplemented}
+	}
+	return sl.Symlink(oldname, newname)
+}
+
+// hookedFile runs the hooks around the calls on a File opened through a
+// hookedFS.
+type hookedFile struct {
+	absfs.File
+	h *Hooks
+}
+
+func (f *hookedFile) Read(b []byte) (n int, err error) {
+	defer f.h.begin("read", f.Name())(&err)
+	return f.File.Read(b)
+}
+
+func (f *hookedFile) ReadAt(b []byte, off int64) (n int, err error) {
+	defer f.h.begin("readat", f.Name())(&err)
+	return f.File.ReadAt(b, off)
+}
+
+func (f *hookedFile) Write(b []byte) (n int, err error) {
+	defer f.h.begin("write", f.Name())(&err)
+	return f.File.Write(b)
+}
+
+func (f *hookedFile) WriteAt(b []byte, off int64) (n int, err error) {
+	defer f.h.begin("writeat", f.Name())(&err)
+	return f.File.WriteAt(b, off)
+}
+
+func (f *hookedFile) WriteString(s string) (n int, err error) {
+	defer f.h.begin("write", f.Name())(&err)
+	return f.File.WriteString(s)
+}
+
+func (f *hookedFile) Seek(offset int64, whence int) (ret int64, err error) {
+	defer f.h.begin("seek", f.Name())(&err)
+	return f.File.Seek(offset, whence)
+}
+
+func (f *hookedFile) Readdir(n int) (infos []os.FileInfo, err error) {
+	defer f.h.begin("readdir", f.Name())(&err)
+	return f.File.Readdir(n)
+}
+
+func (f *hookedFile) Readdirnames(n int) (names []string, err error) {
+	defer f.h.begin("readdirnames", f.Name())(&err)
+	return f.File.Readdirnames(n)
+}
+
+func (f *hookedFile) Stat() (info os.FileInfo, err error) {
+	defer f.h.begin("fstat", f.Name())(&err)
+	return f.File.Stat()
+}
+
+func (f *hookedFile) Truncate(size int64) (err error) {
+	defer f.h.begin("ftruncate", f.Name())(&err)
+	return f.File.Truncate(size)
+}
+
+func (f *hookedFile) Sync() (err error) {
+	defer f.h.begin("sync", f.Name())(&err)
+	return f.File.Sync()
+}
+
+func (f *hookedFile) Close() (err error) {
+	defer f.h.begin("close", f.Name())(&err)
+	return f.File.Close()
+}
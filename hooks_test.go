@@ -0,0 +1,77 @@
+package osfs_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/absfs/osfs"
+)
+
+func TestWithHooks(t *testing.T) {
+	base, _ := newTempFS(t)
+
+	var calls []string
+	fs := osfs.WithHooks(base, osfs.Hooks{
+		Before: func(op, path string) {
+			calls = append(calls, fmt.Sprintf("before %s %s", op, path))
+		},
+		After: func(op, path string, err error) {
+			calls = append(calls, fmt.Sprintf("after %s %s %v", op, path, err != nil))
+		},
+	})
+
+	if err := fs.Mkdir("dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	_, err := fs.Stat("missing")
+	if !os.IsNotExist(err) {
+		t.Fatalf("Stat(missing) error = %v", err)
+	}
+	if err := fs.Rename("dir", "dir2"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"before mkdir dir",
+		"after mkdir dir false",
+		"before stat missing",
+		"after stat missing true",
+		"before rename dir",
+		"after rename dir false",
+	}
+	if got := strings.Join(calls, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("hooks called\n%s\nwant\n%s", got, strings.Join(want, "\n"))
+	}
+
+	// The error passed to After is the one returned.
+	var afterErr error
+	fs = osfs.WithHooks(base, osfs.Hooks{
+		After: func(op, path string, err error) { afterErr = err },
+	})
+	_, err = fs.Open("missing")
+	if err == nil || afterErr != err {
+		t.Errorf("After got %v, want the returned error %v", afterErr, err)
+	}
+
+	// Calls on opened files are hooked with the file's name.
+	calls = nil
+	fs = osfs.WithHooks(base, osfs.Hooks{
+		Before: func(op, path string) { calls = append(calls, op) },
+	})
+	f, err := fs.Create("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("data")
+	f.Close()
+	if got, want := strings.Join(calls, ","), "create,write,close"; got != want {
+		t.Errorf("hooked calls %s, want %s", got, want)
+	}
+
+	// Without callbacks the hooks are no-ops.
+	fs = osfs.WithHooks(base, osfs.Hooks{})
+	if _, err := fs.Stat("file"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,316 @@
+package osfs
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/absfs/absfs"
+)
+
+// latencyBounds are the upper bounds of the buckets of OpStats.Latency.
+var latencyBounds = [...]time.Duration{
+	10 * time.Microsecond,
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+}
+
+// OpStats are the statistics of one kind of operation recorded by Metrics.
+type OpStats struct {
+	// Count is the number of calls and Errors the number of those that
+	// returned an error. End of file is not counted as an error.
+	Count  int64
+	Errors int64
+
+	// Total is the time spent in all calls together and Max the time of
+	// the slowest call.
+	Total time.Duration
+	Max   time.Duration
+
+	// Latency is a histogram of call durations. Its buckets count the calls
+	// that took up to 10µs, 100µs, 1ms, 10ms, 100ms and 1s, each excluding
+	// the calls counted by the buckets before it; the last bucket counts
+	// the calls that took longer.
+	Latency [len(latencyBounds) + 1]int64
+}
+
+// Mean returns the mean duration of a call, or 0 if there were none.
+func (s OpStats) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+func (s *OpStats) add(d time.Duration, err error) {
+	s.Count++
+	if err != nil {
+		s.Errors++
+	}
+	s.Total += d
+	if d > s.Max {
+		s.Max = d
+	}
+	i := 0
+	for i < len(latencyBounds) && d > latencyBounds[i] {
+		i++
+	}
+	s.Latency[i]++
+}
+
+// Metrics collects the statistics of a FileSystem returned by Instrumented.
+// It is safe for concurrent use.
+type Metrics struct {
+	mu  sync.Mutex
+	ops map[string]*OpStats
+}
+
+// MetricsSnapshot is a copy of the statistics held by a Metrics, keyed by
+// operation: "open", "create", "stat", "readdir", "read", "write" and so on.
+// Operations that were never called are absent.
+type MetricsSnapshot struct {
+	Ops map[string]OpStats
+}
+
+// Snapshot returns a copy of the statistics collected so far.
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := MetricsSnapshot{Ops: make(map[string]OpStats, len(m.ops))}
+	for op, stats := range m.ops {
+		s.Ops[op] = *stats
+	}
+	return s
+}
+
+// done records a call of op that started at start and returned *err. It
+// is deferred with the address of the named error result of the call.
+func (m *Metrics) done(op string, start time.Time, errp *error) {
+	d := time.Since(start)
+	err := *errp
+	if err == io.EOF {
+		err = nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.ops[op]
+	if s == nil {
+		s = new(OpStats)
+		m.ops[op] = s
+	}
+	s.add(d, err)
+}
+
+// Instrumented returns a FileSystem that forwards every call to base and
+// records how often each operation is called, how many calls fail and how
+// long they take in the returned Metrics. Operations are named after the
+// methods, in lower case, with the calls on opened Files named "read",
+// "readat", "write", "writeat", "readdir", "readdirnames", "seek", "sync"
+// and "close", and their Stat and Truncate recorded as "fstat" and
+// "ftruncate". Open and OpenFile are both recorded as "open".
+//
+// The returned FileSystem also implements absfs.SymLinker; if base does
+// not, its symlink methods fail with absfs.ErrNotImplemented.
+func Instrumented(base absfs.FileSystem) (absfs.FileSystem, *Metrics) {
+	m := &Metrics{ops: make(map[string]*OpStats)}
+	return &instrumentedFS{base: base, m: m}, m
+}
+
+type instrumentedFS struct {
+	base absfs.FileSystem
+	m    *Metrics
+}
+
+// file wraps a File returned by base.
+func (i *instrumentedFS) file(f absfs.File, err error) (absfs.File, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedFile{File: f, m: i.m}, nil
+}
+
+func (i *instrumentedFS) OpenFile(name string, flag int, perm os.FileMode) (f absfs.File, err error) {
+	defer i.m.done("open", time.Now(), &err)
+	return i.file(i.base.OpenFile(name, flag, perm))
+}
+
+func (i *instrumentedFS) Open(name string) (f absfs.File, err error) {
+	defer i.m.done("open", time.Now(), &err)
+	return i.file(i.base.Open(name))
+}
+
+func (i *instrumentedFS) Create(name string) (f absfs.File, err error) {
+	defer i.m.done("create", time.Now(), &err)
+	return i.file(i.base.Create(name))
+}
+
+func (i *instrumentedFS) Mkdir(name string, perm os.FileMode) (err error) {
+	defer i.m.done("mkdir", time.Now(), &err)
+	return i.base.Mkdir(name, perm)
+}
+
+func (i *instrumentedFS) MkdirAll(name string, perm os.FileMode) (err error) {
+	defer i.m.done("mkdirall", time.Now(), &err)
+	return i.base.MkdirAll(name, perm)
+}
+
+func (i *instrumentedFS) Remove(name string) (err error) {
+	defer i.m.done("remove", time.Now(), &err)
+	return i.base.Remove(name)
+}
+
+func (i *instrumentedFS) RemoveAll(name string) (err error) {
+	defer i.m.done("removeall", time.Now(), &err)
+	return i.base.RemoveAll(name)
+}
+
+func (i *instrumentedFS) Rename(oldpath, newpath string) (err error) {
+	defer i.m.done("rename", time.Now(), &err)
+	return i.base.Rename(oldpath, newpath)
+}
+
+func (i *instrumentedFS) Stat(name string) (info os.FileInfo, err error) {
+	defer i.m.done("stat", time.Now(), &err)
+	return i.base.Stat(name)
+}
+
+func (i *instrumentedFS) Chmod(name string, mode os.FileMode) (err error) {
+	defer i.m.done("chmod", time.Now(), &err)
+	return i.base.Chmod(name, mode)
+}
+
+func (i *instrumentedFS) Chtimes(name string, atime time.Time, mtime time.Time) (err error) {
+	defer i.m.done("chtimes", time.Now(), &err)
+	return i.base.Chtimes(name, atime, mtime)
+}
+
+func (i *instrumentedFS) Chown(name string, uid, gid int) (err error) {
+	defer i.m.done("chown", time.Now(), &err)
+	return i.base.Chown(name, uid, gid)
+}
+
+func (i *instrumentedFS) Truncate(name string, size int64) (err error) {
+	defer i.m.done("truncate", time.Now(), &err)
+	return i.base.Truncate(name, size)
+}
+
+func (i *instrumentedFS) Chdir(dir string) (err error) {
+	defer i.m.done("chdir", time.Now(), &err)
+	return i.base.Chdir(dir)
+}
+
+func (i *instrumentedFS) Getwd() (dir string, err error) {
+	defer i.m.done("getwd", time.Now(), &err)
+	return i.base.Getwd()
+}
+
+func (i *instrumentedFS) Separator() uint8     { return i.base.Separator() }
+func (i *instrumentedFS) ListSeparator() uint8 { return i.base.ListSeparator() }
+func (i *instrumentedFS) TempDir() string      { return i.base.TempDir() }
+
+func (i *instrumentedFS) Lstat(name string) (info os.FileInfo, err error) {
+	defer i.m.done("lstat", time.Now(), &err)
+	sl, ok := i.base.(absfs.SymLinker)
+	if !ok {
+		return nil, &os.PathError{Op: "lstat", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	return sl.Lstat(name)
+}
+
+func (i *instrumentedFS) Lchown(name string, uid, gid int) (err error) {
+	defer i.m.done("lchown", time.Now(), &err)
+	sl, ok := i.base.(absfs.SymLinker)
+	if !ok {
+		return &os.PathError{Op: "lchown", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	return sl.Lchown(name, uid, gid)
+}
+
+func (i *instrumentedFS) Readlink(name string) (target string, err error) {
+	defer i.m.done("readlink", time.Now(), &err)
+	sl, ok := i.base.(absfs.SymLinker)
+	if !ok {
+		return "", &os.PathError{Op: "readlink", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	return sl.Readlink(name)
+}
+
+func (i *instrumentedFS) Symlink(oldname, newname string) (err error) {
+	defer i.m.done("symlink", time.Now(), &err)
+	sl, ok := i.base.(absfs.SymLinker)
+	if !ok {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: absfs.ErrNotImplemented}
+	}
+	return sl.Symlink(oldname, newname)
+}
+
+// instrumentedFile records the calls on a File opened through an
+// instrumentedFS.
+type instrumentedFile struct {
+	absfs.File
+	m *Metrics
+}
+
+func (f *instrumentedFile) Read(b []byte) (n int, err error) {
+	defer f.m.done("read", time.Now(), &err)
+	return f.File.Read(b)
+}
+
+func (f *instrumentedFile) ReadAt(b []byte, off int64) (n int, err error) {
+	defer f.m.done("readat", time.Now(), &err)
+	return f.File.ReadAt(b, off)
+}
+
+func (f *instrumentedFile) Write(b []byte) (n int, err error) {
+	defer f.m.done("write", time.Now(), &err)
+	return f.File.Write(b)
+}
+
+func (f *instrumentedFile) WriteAt(b []byte, off int64) (n int, err error) {
+	defer f.m.done("writeat", time.Now(), &err)
+	return f.File.WriteAt(b, off)
+}
+
+func (f *instrumentedFile) WriteString(s string) (n int, err error) {
+	defer f.m.done("write", time.Now(), &err)
+	return f.File.WriteString(s)
+}
+
+func (f *instrumentedFile) Seek(offset int64, whence int) (ret int64, err error) {
+	defer f.m.done("seek", time.Now(), &err)
+	return f.File.Seek(offset, whence)
+}
+
+func (f *instrumentedFile) Readdir(n int) (infos []os.FileInfo, err error) {
+	defer f.m.done("readdir", time.Now(), &err)
+	return f.File.Readdir(n)
+}
+
+func (f *instrumentedFile) Readdirnames(n int) (names []string, err error) {
+	defer f.m.done("readdirnames", time.Now(), &err)
+	return f.File.Readdirnames(n)
+}
+
+func (f *instrumentedFile) Stat() (info os.FileInfo, err error) {
+	defer f.m.done("fstat", time.Now(), &err)
+	return f.File.Stat()
+}
+
+func (f *instrumentedFile) Truncate(size int64) (err error) {
+	defer f.m.done("ftruncate", time.Now(), &err)
+	return f.File.Truncate(size)
+}
+
+func (f *instrumentedFile) Sync() (err error) {
+	defer f.m.done("sync", time.Now(), &err)
+	return f.File.Sync()
+}
+
+func (f *instrumentedFile) Close() (err error) {
+	defer f.m.done("close", time.Now(), &err)
+	return f.File.Close()
+}
@@ -0,0 +1,82 @@
+package osfs_test
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/absfs/osfs"
+)
+
+func TestInstrumented(t *testing.T) {
+	base, _ := newTempFS(t)
+	fs, m := osfs.Instrumented(base)
+
+	for i := 0; i < 3; i++ {
+		f, err := fs.Create("file")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte("data")); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+	if _, err := fs.Stat("file"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.Stat("missing"); !os.IsNotExist(err) {
+		t.Fatalf("Stat(missing) error = %v", err)
+	}
+	f, err := fs.Open("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(f); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	d, err := fs.Open(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Readdir(-1)
+	d.Close()
+
+	s := m.Snapshot()
+	for op, want := range map[string]struct{ count, errors int64 }{
+		"create":  {3, 0},
+		"write":   {3, 0},
+		"close":   {5, 0},
+		"stat":    {2, 1},
+		"open":    {2, 0},
+		"readdir": {1, 0},
+	} {
+		got := s.Ops[op]
+		if got.Count != want.count || got.Errors != want.errors {
+			t.Errorf("%s: %d calls, %d errors; want %d, %d", op, got.Count, got.Errors, want.count, want.errors)
+		}
+		var n int64
+		for _, c := range got.Latency {
+			n += c
+		}
+		if n != got.Count {
+			t.Errorf("%s: latency histogram holds %d calls, want %d", op, n, got.Count)
+		}
+		if got.Max > got.Total || got.Mean() > got.Max {
+			t.Errorf("%s: inconsistent durations: total %v, max %v, mean %v", op, got.Total, got.Max, got.Mean())
+		}
+	}
+	if r := s.Ops["read"]; r.Count == 0 || r.Errors != 0 {
+		t.Errorf("read: %d calls, %d errors; want some calls and no errors at EOF", r.Count, r.Errors)
+	}
+	if _, ok := s.Ops["mkdir"]; ok {
+		t.Error("snapshot has an entry for an operation that was never called")
+	}
+
+	// A snapshot is a copy.
+	fs.Stat("file")
+	if s.Ops["stat"].Count != 2 {
+		t.Error("snapshot changed after a later call")
+	}
+}
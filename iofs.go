@@ -0,0 +1,65 @@
+package osfs
+
+import (
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// FS returns an io/fs.FS rooted at the current working directory of fs, so
+// the tree can be handed to fs.WalkDir, fs.Glob, http.FS and other io/fs
+// consumers. Names follow the io/fs rules: slash separated, unrooted and
+// cleaned. Later changes to the working directory of fs do not move the
+// root of the returned FS. Besides fs.FS it implements fs.ReadDirFS,
+// fs.ReadFileFS and fs.StatFS.
+func (fs *FileSystem) FS() iofs.FS {
+	return dirFS(fs.cwd)
+}
+
+// dirFS is an io/fs view of the native directory it names.
+type dirFS string
+
+func (dir dirFS) join(op, name string) (string, error) {
+	if !iofs.ValidPath(name) || runtime.GOOS == "windows" && strings.ContainsAny(name, `\:`) {
+		return "", &os.PathError{Op: op, Path: name, Err: iofs.ErrInvalid}
+	}
+	return filepath.Join(string(dir), filepath.FromSlash(name)), nil
+}
+
+func (dir dirFS) Open(name string) (iofs.File, error) {
+	path, err := dir.join("open", name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (dir dirFS) ReadDir(name string) ([]iofs.DirEntry, error) {
+	path, err := dir.join("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadDir(path)
+}
+
+func (dir dirFS) ReadFile(name string) ([]byte, error) {
+	path, err := dir.join("readfile", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+func (dir dirFS) Stat(name string) (iofs.FileInfo, error) {
+	path, err := dir.join("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Stat(path)
+}
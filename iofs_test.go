@@ -0,0 +1,47 @@
+package osfs_test
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFS(t *testing.T) {
+	osfs, _ := newTempFS(t)
+
+	if err := osfs.MkdirAll("dir/sub", 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.txt", "dir/b.txt", "dir/sub/c.txt"} {
+		if err := osfs.WriteFile(name, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fsys := osfs.FS()
+	if err := fstest.TestFS(fsys, "a.txt", "dir/b.txt", "dir/sub/c.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	var walked []string
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		walked = append(walked, path)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(walked) != 6 {
+		t.Errorf("WalkDir visited %q", walked)
+	}
+
+	for _, name := range []string{"/a.txt", "../a.txt", "dir/../a.txt", "dir/"} {
+		if _, err := fsys.Open(name); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("Open(%q): err = %v, want %v", name, err, fs.ErrInvalid)
+		}
+		if _, err := fs.ReadFile(fsys, name); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("ReadFile(%q): err = %v, want %v", name, err, fs.ErrInvalid)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+//go:build !windows
+// +build !windows
+
+package osfs
+
+import (
+	"io"
+	"os"
+)
+
+// IsEmpty reports whether the named directory contains no entries. It stops
+// reading as soon as the first entry is found.
+func (fs *FileSystem) IsEmpty(name string) (bool, error) {
+	path, err := fs.fixPathErr("isempty", name)
+	if err != nil {
+		return false, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	_, err = f.Readdirnames(1)
+	if err == io.EOF {
+		return true, nil
+	}
+	return false, err
+}
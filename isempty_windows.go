@@ -0,0 +1,50 @@
+package osfs
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// IsEmpty reports whether the named directory contains no entries. It stops
+// enumerating as soon as FindFirstFile/FindNextFile return an entry other
+// than `.` or `..`, so large directories are not read in full.
+func (fs *FileSystem) IsEmpty(name string) (bool, error) {
+	dir, err := fs.fixPathErr("isempty", name)
+	if err != nil {
+		return false, err
+	}
+	pattern, err := syscall.UTF16PtrFromString(filepath.Join(dir, "*"))
+	if err != nil {
+		return false, &os.PathError{Op: "isempty", Path: dir, Err: err}
+	}
+
+	var fd syscall.Win32finddata
+	h, err := syscall.FindFirstFile(pattern, &fd)
+	if err == syscall.ERROR_FILE_NOT_FOUND {
+		// The root of an empty volume has no . or .. entries, so nothing
+		// matches at all.
+		if info, serr := os.Stat(dir); serr == nil && info.IsDir() {
+			return true, nil
+		}
+	}
+	if err != nil {
+		return false, &os.PathError{Op: "isempty", Path: dir, Err: err}
+	}
+	defer syscall.FindClose(h)
+
+	for {
+		switch syscall.UTF16ToString(fd.FileName[:]) {
+		case ".", "..":
+		default:
+			return false, nil
+		}
+		err := syscall.FindNextFile(h, &fd)
+		if err == syscall.ERROR_NO_MORE_FILES {
+			return true, nil
+		}
+		if err != nil {
+			return false, &os.PathError{Op: "isempty", Path: dir, Err: err}
+		}
+	}
+}
@@ -0,0 +1,22 @@
+//go:build dragonfly || freebsd || netbsd
+// +build dragonfly freebsd netbsd
+
+package osfs
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+func lchflags(path string, flags uint32) error {
+	p, err := unix.BytePtrFromString(path)
+	if err != nil {
+		return chflagsError("lchflags", path, err)
+	}
+	_, _, errno := unix.Syscall(unix.SYS_LCHFLAGS, uintptr(unsafe.Pointer(p)), uintptr(flags), 0)
+	if errno != 0 {
+		return chflagsError("lchflags", path, errno)
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package osfs
+
+import "golang.org/x/sys/unix"
+
+// lchflags opens a symbolic link itself with O_SYMLINK, as macOS has no
+// lchflags system call.
+func lchflags(path string, flags uint32) error {
+	fd, err := unix.Open(path, unix.O_RDONLY|unix.O_SYMLINK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return chflagsError("lchflags", path, err)
+	}
+	defer unix.Close(fd)
+	if err := unix.Fchflags(fd, int(flags)); err != nil {
+		return chflagsError("lchflags", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,7 @@
+package osfs
+
+import "os"
+
+func lchflags(path string, flags uint32) error {
+	return &os.PathError{Op: "lchflags", Path: path, Err: ErrNotSupported}
+}
@@ -0,0 +1,19 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris && !windows
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris,!windows
+
+package osfs
+
+import (
+	"os"
+	"time"
+)
+
+// Lchtimes changes the times of a symbolic link itself. It is not supported
+// on this platform.
+func (fs *FileSystem) Lchtimes(name string, atime, mtime time.Time) error {
+	path, err := fs.fixPathErr("lchtimes", name)
+	if err != nil {
+		return err
+	}
+	return &os.PathError{Op: "lchtimes", Path: path, Err: ErrNotSupported}
+}
@@ -0,0 +1,36 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package osfs
+
+import (
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Lchtimes changes the access and modification times of the named file like
+// Chtimes, but if the file is a symbolic link it changes the times of the
+// link itself rather than of its target. A zero time leaves that time
+// unchanged.
+func (fs *FileSystem) Lchtimes(name string, atime, mtime time.Time) error {
+	path, err := fs.fixPathErr("lchtimes", name)
+	if err != nil {
+		return err
+	}
+	ts := []unix.Timespec{utimeSpec(atime), utimeSpec(mtime)}
+	if err := unix.UtimesNanoAt(unix.AT_FDCWD, path, ts, unix.AT_SYMLINK_NOFOLLOW); err != nil {
+		return &os.PathError{Op: "lchtimes", Path: path, Err: err}
+	}
+	return nil
+}
+
+// utimeSpec converts t for UtimesNanoAt, mapping the zero time to
+// UTIME_OMIT.
+func utimeSpec(t time.Time) unix.Timespec {
+	if t.IsZero() {
+		return unix.Timespec{Nsec: utimeOmit}
+	}
+	return unix.NsecToTimespec(t.UnixNano())
+}
@@ -0,0 +1,44 @@
+package osfs
+
+import (
+	"os"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// Lchtimes changes the access and modification times of the named file like
+// Chtimes, but if the file is a symbolic link it changes the times of the
+// link itself rather than of its target. A zero time leaves that time
+// unchanged.
+func (fs *FileSystem) Lchtimes(name string, atime, mtime time.Time) error {
+	path, err := fs.fixPathErr("lchtimes", name)
+	if err != nil {
+		return err
+	}
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return &os.PathError{Op: "lchtimes", Path: path, Err: err}
+	}
+	h, err := windows.CreateFile(p, windows.FILE_WRITE_ATTRIBUTES, windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE,
+		nil, windows.OPEN_EXISTING, windows.FILE_FLAG_OPEN_REPARSE_POINT|windows.FILE_FLAG_BACKUP_SEMANTICS, 0)
+	if err != nil {
+		return &os.PathError{Op: "lchtimes", Path: path, Err: err}
+	}
+	defer windows.CloseHandle(h)
+
+	if err := windows.SetFileTime(h, nil, fileTime(atime), fileTime(mtime)); err != nil {
+		return &os.PathError{Op: "lchtimes", Path: path, Err: err}
+	}
+	return nil
+}
+
+// fileTime converts t for SetFileTime, mapping the zero time to nil, which
+// leaves the time unchanged.
+func fileTime(t time.Time) *windows.Filetime {
+	if t.IsZero() {
+		return nil
+	}
+	ft := windows.NsecToFiletime(t.UnixNano())
+	return &ft
+}
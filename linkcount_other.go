@@ -0,0 +1,16 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris && !windows
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris,!windows
+
+package osfs
+
+import "os"
+
+// LinkCount returns the number of hard links to the named file. It is not
+// supported on this platform.
+func (fs *FileSystem) LinkCount(name string) (uint64, error) {
+	path, err := fs.fixPathErr("linkcount", name)
+	if err != nil {
+		return 0, err
+	}
+	return 0, &os.PathError{Op: "linkcount", Path: path, Err: ErrNotSupported}
+}
@@ -0,0 +1,27 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package osfs
+
+import (
+	"os"
+	"syscall"
+)
+
+// LinkCount returns the number of hard links to the named file, following
+// symbolic links.
+func (fs *FileSystem) LinkCount(name string) (uint64, error) {
+	path, err := fs.fixPathErr("linkcount", name)
+	if err != nil {
+		return 0, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, &os.PathError{Op: "linkcount", Path: path, Err: ErrNotSupported}
+	}
+	return uint64(st.Nlink), nil
+}
@@ -0,0 +1,32 @@
+package osfs
+
+import (
+	"os"
+	"syscall"
+)
+
+// LinkCount returns the number of hard links to the named file, following
+// symbolic links.
+func (fs *FileSystem) LinkCount(name string) (uint64, error) {
+	path, err := fs.fixPathErr("linkcount", name)
+	if err != nil {
+		return 0, err
+	}
+	p, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, &os.PathError{Op: "linkcount", Path: path, Err: err}
+	}
+
+	share := uint32(syscall.FILE_SHARE_READ | syscall.FILE_SHARE_WRITE | syscall.FILE_SHARE_DELETE)
+	h, err := syscall.CreateFile(p, 0, share, nil, syscall.OPEN_EXISTING, syscall.FILE_FLAG_BACKUP_SEMANTICS, 0)
+	if err != nil {
+		return 0, &os.PathError{Op: "linkcount", Path: path, Err: err}
+	}
+	defer syscall.CloseHandle(h)
+
+	var d syscall.ByHandleFileInformation
+	if err := syscall.GetFileInformationByHandle(h, &d); err != nil {
+		return 0, &os.PathError{Op: "linkcount", Path: path, Err: err}
+	}
+	return uint64(d.NumberOfLinks), nil
+}
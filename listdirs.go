@@ -0,0 +1,52 @@
+package osfs
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ListDirs returns the sorted absfs paths of every directory below root, not
+// including root itself. Only directory entries are descended into; symbolic
+// links to directories are not followed.
+func (fs *FileSystem) ListDirs(root string) ([]string, error) {
+	return fs.ListDirsDepth(root, -1)
+}
+
+// ListDirsDepth is like ListDirs but descends at most maxDepth levels below
+// root, so a maxDepth of 1 lists only the immediate subdirectories. A
+// negative maxDepth means no limit.
+func (fs *FileSystem) ListDirsDepth(root string, maxDepth int) ([]string, error) {
+	path, err := fs.fixPathErr("open", root)
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	if maxDepth != 0 {
+		if err := listDirs(path, 1, maxDepth, &dirs); err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+func listDirs(dir string, depth, maxDepth int, dirs *[]string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		*dirs = append(*dirs, FromNative(path))
+		if maxDepth < 0 || depth < maxDepth {
+			if err := listDirs(path, depth+1, maxDepth, dirs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package osfs
+
+import (
+	"errors"
+	"os"
+
+	"github.com/absfs/absfs"
+)
+
+// ErrLocked is returned, wrapped in an *os.PathError, by File.TryLock when
+// the file is locked by someone else.
+var ErrLocked = errors.New("file is locked")
+
+// OpenLocked opens the named file like OpenFile and places an advisory lock
+// on it before returning, exclusive if exclusive is set and shared
+// otherwise. It blocks until the lock is granted. If flag contains
+// os.O_TRUNC the file is truncated only once the lock is held, so a reader
+// holding a shared lock never sees a half-truncated file. Closing the
+// returned File releases the lock.
+//
+// On Unix the lock is advisory: it only excludes others that lock the file
+// as well.
+func (fs *FileSystem) OpenLocked(name string, flag int, perm os.FileMode, exclusive bool) (absfs.File, error) {
+	path, err := fs.fixPathErr("open", name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, flag&^os.O_TRUNC, perm)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f, exclusive, true); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if flag&os.O_TRUNC != 0 {
+		if err := f.Truncate(0); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return &File{fs, f}, nil
+}
+
+// Lock places an exclusive advisory lock on the file, blocking until any
+// conflicting lock held through another open file is released. Locks are
+// held per open file, not per process, and are released by Unlock or when
+// the file is closed. Use OpenLocked for shared locks.
+func (f *File) Lock() error {
+	return lockFile(f.f, true, true)
+}
+
+// TryLock is Lock without the wait: if the lock cannot be granted
+// immediately it fails with an error wrapping ErrLocked.
+func (f *File) TryLock() error {
+	return lockFile(f.f, true, false)
+}
+
+// Unlock releases a lock placed by Lock, TryLock or OpenLocked.
+func (f *File) Unlock() error {
+	return unlockFile(f.f)
+}
@@ -0,0 +1,154 @@
+package osfs
+
+import (
+	"errors"
+	"io"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// MergeFS returns a read-only io/fs.FS that layers the directories roots on
+// top of each other, as a union mount would. A file is served from the first
+// root that contains the name, while the contents of a directory are merged
+// across every root in which the name is a directory, entries from earlier
+// roots shadowing same-named entries from later ones. Names are validated as
+// for FS, so no root can be escaped with `..`. The result implements
+// fs.ReadDirFS, fs.ReadFileFS and fs.StatFS.
+func MergeFS(roots ...string) (iofs.FS, error) {
+	if len(roots) == 0 {
+		return nil, errors.New("osfs: MergeFS needs at least one root")
+	}
+	m := make(mergeFS, len(roots))
+	for i, root := range roots {
+		dir, err := filepath.Abs(ToNative(root))
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			return nil, &os.PathError{Op: "mergefs", Path: root, Err: errors.New("not a directory")}
+		}
+		m[i] = dirFS(dir)
+	}
+	return m, nil
+}
+
+// mergeFS is a union of directories, highest priority first.
+type mergeFS []dirFS
+
+// lookup returns the index and info of the first root that contains name.
+func (m mergeFS) lookup(op, name string) (int, iofs.FileInfo, error) {
+	for i, dir := range m {
+		info, err := dir.Stat(name)
+		if err == nil {
+			return i, info, nil
+		}
+		if !os.IsNotExist(err) {
+			return 0, nil, err
+		}
+	}
+	return 0, nil, &os.PathError{Op: op, Path: name, Err: iofs.ErrNotExist}
+}
+
+func (m mergeFS) Open(name string) (iofs.File, error) {
+	i, info, err := m.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return m[i].Open(name)
+	}
+	entries, err := m.readDir(i, name)
+	if err != nil {
+		return nil, err
+	}
+	return &mergedDir{name: name, info: info, entries: entries}, nil
+}
+
+func (m mergeFS) ReadDir(name string) ([]iofs.DirEntry, error) {
+	i, info, err := m.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, &os.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	return m.readDir(i, name)
+}
+
+// readDir merges the listings of name in m[first:], skipping roots in which
+// name is not a directory.
+func (m mergeFS) readDir(first int, name string) ([]iofs.DirEntry, error) {
+	seen := make(map[string]bool)
+	var entries []iofs.DirEntry
+	for _, dir := range m[first:] {
+		info, err := dir.Stat(name)
+		if os.IsNotExist(err) || err == nil && !info.IsDir() {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		list, err := dir.ReadDir(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range list {
+			if !seen[e.Name()] {
+				seen[e.Name()] = true
+				entries = append(entries, e)
+			}
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+func (m mergeFS) ReadFile(name string) ([]byte, error) {
+	i, _, err := m.lookup("readfile", name)
+	if err != nil {
+		return nil, err
+	}
+	return m[i].ReadFile(name)
+}
+
+func (m mergeFS) Stat(name string) (iofs.FileInfo, error) {
+	_, info, err := m.lookup("stat", name)
+	return info, err
+}
+
+// mergedDir is an open directory of a mergeFS.
+type mergedDir struct {
+	name    string
+	info    iofs.FileInfo
+	entries []iofs.DirEntry
+	offset  int
+}
+
+func (d *mergedDir) Stat() (iofs.FileInfo, error) { return d.info, nil }
+func (d *mergedDir) Close() error                 { return nil }
+
+func (d *mergedDir) Read([]byte) (int, error) {
+	return 0, &os.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+
+func (d *mergedDir) ReadDir(n int) ([]iofs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.offset += n
+	return rest[:n], nil
+}
@@ -0,0 +1,84 @@
+package osfs_test
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/absfs/osfs"
+)
+
+func TestMergeFS(t *testing.T) {
+	ofs, _ := newTempFS(t)
+
+	files := map[string]string{
+		"top/a.txt":        "top",
+		"top/dir/b.txt":    "top",
+		"top/shadow":       "file",
+		"bottom/a.txt":     "bottom",
+		"bottom/c.txt":     "bottom",
+		"bottom/dir/b.txt": "bottom",
+		"bottom/dir/d.txt": "bottom",
+		"bottom/shadow/e":  "hidden",
+	}
+	for name, data := range files {
+		if err := ofs.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ofs.WriteFile(name, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	top, _ := ofs.Abs("top")
+	bottom, _ := ofs.Abs("bottom")
+	fsys, err := osfs.MergeFS(top, bottom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fstest.TestFS(fsys, "a.txt", "c.txt", "dir/b.txt", "dir/d.txt", "shadow"); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{
+		"a.txt":     "top",
+		"c.txt":     "bottom",
+		"dir/b.txt": "top",
+		"dir/d.txt": "bottom",
+		"shadow":    "file",
+	} {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			t.Errorf("ReadFile(%q): %v", name, err)
+		} else if string(data) != want {
+			t.Errorf("ReadFile(%q) = %q, want %q", name, data, want)
+		}
+	}
+
+	entries, err := fs.ReadDir(fsys, "dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if got := strings.Join(names, ","); got != "b.txt,d.txt" {
+		t.Errorf("ReadDir(dir) = %s, want b.txt,d.txt", got)
+	}
+
+	if _, err := fs.Stat(fsys, "shadow/e"); err == nil {
+		t.Errorf("shadow/e should be hidden by the file shadow")
+	}
+	if _, err := fsys.Open("../top/a.txt"); err == nil {
+		t.Error("Open escaped the roots")
+	}
+	if _, err := osfs.MergeFS(); err == nil {
+		t.Error("MergeFS() succeeded without roots")
+	}
+	if _, err := osfs.MergeFS(top + "/a.txt"); err == nil {
+		t.Error("MergeFS succeeded with a file root")
+	}
+}
@@ -0,0 +1,64 @@
+package osfs
+
+import "os"
+
+// MapProt selects the access allowed to a memory mapping made by File.Map.
+type MapProt int
+
+const (
+	// MapRead maps the file for reading.
+	MapRead MapProt = 1 << iota
+	// MapWrite maps the file for writing. Writes through the mapping are
+	// shared with the file and with other mappings of it; Flush writes them
+	// back to disk. The file must be open for reading and writing.
+	MapWrite
+)
+
+// MappedRegion is a range of a file mapped into memory by File.Map.
+//
+// The slice returned by Bytes is only valid until Unmap is called; using it
+// afterwards faults. The region must also be unmapped before the file it
+// was made from is closed, and must not be used after that.
+type MappedRegion struct {
+	data []byte // the requested range
+	m    mapping
+}
+
+// Bytes returns the mapped range of the file. Its length is the length
+// passed to Map; writing to it requires a mapping made with MapWrite.
+func (r *MappedRegion) Bytes() []byte {
+	return r.data
+}
+
+// Flush writes changes made through the mapping back to the file and waits
+// until they reach the disk.
+func (r *MappedRegion) Flush() error {
+	return r.m.flush()
+}
+
+// Unmap removes the mapping. The slice returned by Bytes must not be used
+// afterwards.
+func (r *MappedRegion) Unmap() error {
+	r.data = nil
+	return r.m.unmap()
+}
+
+// Map maps length bytes of the file, starting at offset, into memory with
+// the access given by prot. The offset need not be aligned: Map rounds it
+// down to the page size, or to the allocation granularity on Windows, and
+// Bytes returns just the requested range. The range should lie within the
+// file; accessing mapped pages past its end faults on most systems.
+//
+// Map uses mmap(2) on Unix systems and CreateFileMapping and MapViewOfFile
+// on Windows. On other platforms it returns an error wrapping
+// ErrNotSupported.
+func (f *File) Map(offset int64, length int, prot MapProt) (*MappedRegion, error) {
+	if offset < 0 || length <= 0 || prot&^(MapRead|MapWrite) != 0 {
+		return nil, &os.PathError{Op: "mmap", Path: f.f.Name(), Err: os.ErrInvalid}
+	}
+	m, data, err := mapFile(f.f, offset, length, prot)
+	if err != nil {
+		return nil, &os.PathError{Op: "mmap", Path: f.f.Name(), Err: err}
+	}
+	return &MappedRegion{data: data, m: m}, nil
+}
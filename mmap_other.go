@@ -0,0 +1,20 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris && !windows
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris,!windows
+
+package osfs
+
+import "os"
+
+type mapping struct{}
+
+func mapFile(f *os.File, offset int64, length int, prot MapProt) (mapping, []byte, error) {
+	return mapping{}, nil, ErrNotSupported
+}
+
+func (m *mapping) flush() error {
+	return ErrNotSupported
+}
+
+func (m *mapping) unmap() error {
+	return nil
+}
@@ -0,0 +1,50 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package osfs
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// mapping is the whole page-aligned mmap(2) region.
+type mapping struct {
+	b []byte
+}
+
+func mapFile(f *os.File, offset int64, length int, prot MapProt) (mapping, []byte, error) {
+	delta := int(offset % int64(os.Getpagesize()))
+	var p int
+	if prot&MapRead != 0 {
+		p |= unix.PROT_READ
+	}
+	if prot&MapWrite != 0 {
+		p |= unix.PROT_WRITE
+	}
+	b, err := unix.Mmap(int(f.Fd()), offset-int64(delta), length+delta, p, unix.MAP_SHARED)
+	if err != nil {
+		return mapping{}, nil, err
+	}
+	return mapping{b}, b[delta:], nil
+}
+
+func (m *mapping) flush() error {
+	if err := unix.Msync(m.b, unix.MS_SYNC); err != nil {
+		return os.NewSyscallError("msync", err)
+	}
+	return nil
+}
+
+func (m *mapping) unmap() error {
+	if m.b == nil {
+		return nil
+	}
+	b := m.b
+	m.b = nil
+	if err := unix.Munmap(b); err != nil {
+		return os.NewSyscallError("munmap", err)
+	}
+	return nil
+}
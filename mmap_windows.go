@@ -0,0 +1,74 @@
+package osfs
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// allocationGranularity is the alignment Windows requires of the offset of
+// a view of a file.
+const allocationGranularity = 64 << 10
+
+// mapping is the whole view mapped by MapViewOfFile, and the file it maps.
+type mapping struct {
+	addr uintptr
+	size uintptr
+	file windows.Handle
+}
+
+func mapFile(f *os.File, offset int64, length int, prot MapProt) (mapping, []byte, error) {
+	delta := int(offset % allocationGranularity)
+	base := offset - int64(delta)
+	end := offset + int64(length)
+
+	pageProt, access := uint32(windows.PAGE_READONLY), uint32(windows.FILE_MAP_READ)
+	if prot&MapWrite != 0 {
+		pageProt, access = windows.PAGE_READWRITE, windows.FILE_MAP_WRITE
+	}
+	file := windows.Handle(f.Fd())
+	h, err := windows.CreateFileMapping(file, nil, pageProt, uint32(end>>32), uint32(end), nil)
+	if err != nil {
+		return mapping{}, nil, os.NewSyscallError("CreateFileMapping", err)
+	}
+	// The view keeps the mapping object alive.
+	defer windows.CloseHandle(h)
+
+	size := uintptr(length + delta)
+	addr, err := windows.MapViewOfFile(h, access, uint32(base>>32), uint32(base), size)
+	if err != nil {
+		return mapping{}, nil, os.NewSyscallError("MapViewOfFile", err)
+	}
+
+	var b []byte
+	hdr := (*struct {
+		data uintptr
+		len  int
+		cap  int
+	})(unsafe.Pointer(&b))
+	hdr.data, hdr.len, hdr.cap = addr, int(size), int(size)
+	return mapping{addr: addr, size: size, file: file}, b[delta:], nil
+}
+
+func (m *mapping) flush() error {
+	if err := windows.FlushViewOfFile(m.addr, m.size); err != nil {
+		return os.NewSyscallError("FlushViewOfFile", err)
+	}
+	if err := windows.FlushFileBuffers(m.file); err != nil {
+		return os.NewSyscallError("FlushFileBuffers", err)
+	}
+	return nil
+}
+
+func (m *mapping) unmap() error {
+	if m.addr == 0 {
+		return nil
+	}
+	addr := m.addr
+	m.addr = 0
+	if err := windows.UnmapViewOfFile(addr); err != nil {
+		return os.NewSyscallError("UnmapViewOfFile", err)
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package osfs
+
+// IsMountPoint reports whether the named directory is the root of a mounted
+// filesystem, so walks that must stay on one filesystem can stop there. On
+// Unix a directory is a mount point if it is on a different device than its
+// parent, or is its own parent as "/" is; bind mounts of a directory on the
+// same filesystem are not detected. On Windows a directory is a mount point
+// if it is the root of a volume, a drive or a volume mounted in a folder.
+// Files other than directories, including symbolic links, are never mount
+// points.
+func (fs *FileSystem) IsMountPoint(name string) (bool, error) {
+	path, err := fs.fixPathErr("ismountpoint", name)
+	if err != nil {
+		return false, err
+	}
+	return isMountPoint(path)
+}
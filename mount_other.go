@@ -0,0 +1,10 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris && !windows
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris,!windows
+
+package osfs
+
+import "os"
+
+func isMountPoint(path string) (bool, error) {
+	return false, &os.PathError{Op: "ismountpoint", Path: path, Err: ErrNotSupported}
+}
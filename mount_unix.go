@@ -0,0 +1,33 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package osfs
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+func isMountPoint(path string) (bool, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false, err
+	}
+	if !info.IsDir() {
+		return false, nil
+	}
+	parent, err := os.Lstat(filepath.Join(path, ".."))
+	if err != nil {
+		return false, err
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	pst, pok := parent.Sys().(*syscall.Stat_t)
+	if !ok || !pok {
+		return false, &os.PathError{Op: "ismountpoint", Path: path, Err: ErrNotSupported}
+	}
+	if st.Dev != pst.Dev {
+		return true, nil
+	}
+	return st.Ino == pst.Ino, nil
+}
@@ -0,0 +1,30 @@
+package osfs
+
+import (
+	"os"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+func isMountPoint(path string) (bool, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false, err
+	}
+	if !info.IsDir() {
+		return false, nil
+	}
+
+	p, err := windows.UTF16FromString(path)
+	if err != nil {
+		return false, &os.PathError{Op: "ismountpoint", Path: path, Err: err}
+	}
+	// The volume path is a prefix of path, plus a trailing separator.
+	buf := make([]uint16, len(p)+windows.MAX_PATH)
+	if err := windows.GetVolumePathName(&p[0], &buf[0], uint32(len(buf))); err != nil {
+		return false, &os.PathError{Op: "GetVolumePathName", Path: path, Err: err}
+	}
+	vol := strings.TrimRight(windows.UTF16ToString(buf), `\`)
+	return strings.EqualFold(vol, strings.TrimRight(path, `\`)), nil
+}
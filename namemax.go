@@ -0,0 +1,47 @@
+package osfs
+
+import (
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultNameMax is the name length limit reported when the platform cannot
+// be queried. 255 bytes is the limit of nearly every modern filesystem.
+const defaultNameMax = 255
+
+// TruncateName shortens the file name name to at most max bytes, keeping its
+// extension, so that "verylongname.txt" truncated to 12 becomes
+// "verylong.txt". Names are cut on UTF-8 rune boundaries, and trailing spaces
+// and periods, which Windows does not allow, are removed from the shortened
+// part. If the extension alone does not fit, the whole name is truncated.
+func TruncateName(name string, max int) string {
+	if len(name) <= max {
+		return name
+	}
+	if max <= 0 {
+		return ""
+	}
+
+	ext := path.Ext(name)
+	stem := name[:len(name)-len(ext)]
+	if stem != "" && len(ext) < max {
+		stem = strings.TrimRight(truncateUTF8(stem, max-len(ext)), " .")
+		if stem != "" {
+			return stem + ext
+		}
+	}
+	return strings.TrimRight(truncateUTF8(name, max), " .")
+}
+
+// truncateUTF8 returns the longest prefix of s of at most n bytes that does
+// not split a rune.
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,30 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+// +build darwin dragonfly freebsd netbsd openbsd
+
+package osfs
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// _PC_NAME_MAX has the same value on every BSD derived system.
+const _PC_NAME_MAX = 4
+
+// MaxNameLength returns the maximum length in bytes of a single path
+// component on the filesystem containing dir.
+func (fs *FileSystem) MaxNameLength(dir string) (int, error) {
+	path, err := fs.fixPathErr("maxnamelength", dir)
+	if err != nil {
+		return 0, err
+	}
+	n, err := unix.Pathconf(path, _PC_NAME_MAX)
+	if err != nil {
+		return 0, &os.PathError{Op: "maxnamelength", Path: path, Err: err}
+	}
+	if n <= 0 {
+		return defaultNameMax, nil
+	}
+	return n, nil
+}
@@ -0,0 +1,23 @@
+package osfs
+
+import (
+	"os"
+	"syscall"
+)
+
+// MaxNameLength returns the maximum length in bytes of a single path
+// component on the filesystem containing dir.
+func (fs *FileSystem) MaxNameLength(dir string) (int, error) {
+	path, err := fs.fixPathErr("maxnamelength", dir)
+	if err != nil {
+		return 0, err
+	}
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, &os.PathError{Op: "maxnamelength", Path: path, Err: err}
+	}
+	if st.Namelen <= 0 {
+		return defaultNameMax, nil
+	}
+	return int(st.Namelen), nil
+}
@@ -0,0 +1,20 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!windows
+
+package osfs
+
+import "os"
+
+// MaxNameLength returns the maximum length in bytes of a single path
+// component on the filesystem containing dir. The limit cannot be queried on
+// this platform, so a default of 255 is returned for any existing dir.
+func (fs *FileSystem) MaxNameLength(dir string) (int, error) {
+	path, err := fs.fixPathErr("maxnamelength", dir)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return 0, err
+	}
+	return defaultNameMax, nil
+}
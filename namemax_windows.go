@@ -0,0 +1,34 @@
+package osfs
+
+import (
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/windows"
+)
+
+// MaxNameLength returns the maximum length in UTF-16 code units of a single
+// path component on the volume containing dir, as reported by
+// GetVolumeInformation.
+func (fs *FileSystem) MaxNameLength(dir string) (int, error) {
+	path, err := fs.fixPathErr("maxnamelength", dir)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return 0, err
+	}
+	root, err := windows.UTF16PtrFromString(filepath.VolumeName(path) + `\`)
+	if err != nil {
+		return 0, &os.PathError{Op: "maxnamelength", Path: path, Err: err}
+	}
+
+	var n uint32
+	if err := windows.GetVolumeInformation(root, nil, 0, nil, &n, nil, nil, 0); err != nil {
+		return 0, &os.PathError{Op: "maxnamelength", Path: path, Err: err}
+	}
+	if n == 0 {
+		return defaultNameMax, nil
+	}
+	return int(n), nil
+}
@@ -0,0 +1,72 @@
+package osfs
+
+import (
+	"os"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeName returns name in Unicode Normalization Form C, the composed
+// form most text is written in. macOS filesystems may hand names back in
+// the decomposed form instead, so a file created as "caf\u00e9" can be
+// listed as "cafe\u0301", with the accent as a separate combining mark;
+// normalizing both sides makes such names compare equal. Only the string
+// is changed, the filesystem is not consulted.
+func (fs *FileSystem) NormalizeName(name string) string {
+	return norm.NFC.String(name)
+}
+
+// normalize applies NormalizeName to name if NormalizeNames is set.
+func (fs *FileSystem) normalize(name string) string {
+	if !fs.NormalizeNames {
+		return name
+	}
+	return norm.NFC.String(name)
+}
+
+// normalizeInfos applies NormalizeName to the names of infos in place if
+// NormalizeNames is set.
+func (fs *FileSystem) normalizeInfos(infos []os.FileInfo) {
+	if !fs.NormalizeNames {
+		return
+	}
+	for i, info := range infos {
+		if name := norm.NFC.String(info.Name()); name != info.Name() {
+			infos[i] = normalizedInfo{info, name}
+		}
+	}
+}
+
+// normalizeEntry applies NormalizeName to the name of e if NormalizeNames
+// is set.
+func (fs *FileSystem) normalizeEntry(e os.DirEntry) os.DirEntry {
+	if !fs.NormalizeNames {
+		return e
+	}
+	if name := norm.NFC.String(e.Name()); name != e.Name() {
+		return normalizedEntry{e, name}
+	}
+	return e
+}
+
+type normalizedInfo struct {
+	os.FileInfo
+	name string
+}
+
+func (i normalizedInfo) Name() string { return i.name }
+
+type normalizedEntry struct {
+	os.DirEntry
+	name string
+}
+
+func (e normalizedEntry) Name() string { return e.name }
+
+func (e normalizedEntry) Info() (os.FileInfo, error) {
+	info, err := e.DirEntry.Info()
+	if err != nil {
+		return nil, err
+	}
+	return normalizedInfo{info, e.name}, nil
+}
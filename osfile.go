@@ -62,11 +62,28 @@ func (f *File) Sync() error {
 }
 
 func (f *File) Readdir(n int) ([]os.FileInfo, error) {
-	return f.f.Readdir(n)
+	infos, err := f.f.Readdir(n)
+	f.filer.normalizeInfos(infos)
+	return infos, err
+}
+
+// ReadDir reads the contents of the directory and returns up to n entries,
+// like os.File.ReadDir. Successive calls page through the directory from
+// where the last one stopped. If n <= 0 all remaining entries are returned.
+func (f *File) ReadDir(n int) ([]os.DirEntry, error) {
+	entries, err := f.f.ReadDir(n)
+	for i, e := range entries {
+		entries[i] = f.filer.normalizeEntry(e)
+	}
+	return entries, err
 }
 
 func (f *File) Readdirnames(n int) ([]string, error) {
-	return f.f.Readdirnames(n)
+	names, err := f.f.Readdirnames(n)
+	for i, name := range names {
+		names[i] = f.filer.normalize(name)
+	}
+	return names, err
 }
 
 func (f *File) Truncate(size int64) error {
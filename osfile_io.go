@@ -0,0 +1,46 @@
+package osfs
+
+import (
+	"io"
+	"time"
+)
+
+// ReadFrom implements io.ReaderFrom, so io.Copy into a File can use the
+// fast paths of *os.File, such as copy_file_range(2) or splice(2) on Linux,
+// including when r is itself a File.
+func (f *File) ReadFrom(r io.Reader) (int64, error) {
+	if src, ok := r.(*File); ok {
+		r = src.f
+	}
+	return f.f.ReadFrom(r)
+}
+
+// WriteTo implements io.WriterTo, so io.Copy out of a File can use the fast
+// paths of the destination or of *os.File, such as sendfile(2) to a socket,
+// including when w is itself a File.
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	if dst, ok := w.(*File); ok {
+		w = dst.f
+	}
+	return io.Copy(w, f.f)
+}
+
+// SetDeadline sets the read and write deadlines of the file, as
+// os.File.SetDeadline does. Deadlines are only supported by pollable files,
+// such as pipes and FIFOs on most systems; for other files the error wraps
+// os.ErrNoDeadline.
+func (f *File) SetDeadline(t time.Time) error {
+	return f.f.SetDeadline(t)
+}
+
+// SetReadDeadline sets the deadline for future Read calls and any currently
+// blocked Read call, as os.File.SetReadDeadline does.
+func (f *File) SetReadDeadline(t time.Time) error {
+	return f.f.SetReadDeadline(t)
+}
+
+// SetWriteDeadline sets the deadline for future Write calls and any
+// currently blocked Write call, as os.File.SetWriteDeadline does.
+func (f *File) SetWriteDeadline(t time.Time) error {
+	return f.f.SetWriteDeadline(t)
+}
@@ -2,16 +2,55 @@ package osfs
 
 import (
 	"errors"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/absfs/absfs"
 	"github.com/absfs/osfs/fastwalk"
 )
 
+// ErrNotSupported is returned, wrapped in an *os.PathError, by operations
+// that are not available on the current platform.
+var ErrNotSupported = errors.New("operation not supported")
+
+// ErrInvalidPath is returned, wrapped in an *os.PathError, for names that
+// cannot refer to any file: names containing a NUL byte and, on Windows, UNC
+// paths without a share such as `//server`.
+var ErrInvalidPath = errors.New("invalid path")
+
 type FileSystem struct {
 	cwd string
+
+	// TempPattern is the pattern used to name temporary files the
+	// FileSystem creates on its own behalf. The last "*" is replaced by a
+	// random string. If empty, DefaultTempPattern is used.
+	TempPattern string
+
+	// BufferSize is the size of the buffers used to copy data between
+	// files and readers by CopyFile, WriteReader and the methods built on
+	// them. Buffers are pooled and shared by all goroutines using the
+	// FileSystem. If zero, the destination file's preferred I/O size, its
+	// st_blksize, is used where the platform reports one, and
+	// DefaultBufferSize otherwise.
+	BufferSize int
+
+	// SymlinkKind selects the kind of link Symlink creates on Windows,
+	// where file and directory links differ. It is ignored elsewhere.
+	SymlinkKind SymlinkKind
+
+	// NormalizeNames makes directory listings return names in Unicode
+	// Normalization Form C, as NormalizeName does. It applies to
+	// File.Readdir, File.Readdirnames, RangeDir, ReadDirUnsorted and
+	// ReadDirDots. Normalized names only open the same file on filesystems
+	// that ignore normalization, such as those of macOS.
+	NormalizeNames bool
+
+	temps   tempRegistry
+	buffers sync.Pool
 }
 
 func NewFS() (*FileSystem, error) {
@@ -20,7 +59,7 @@ func NewFS() (*FileSystem, error) {
 		return nil, err
 	}
 
-	return &FileSystem{dir}, nil
+	return &FileSystem{cwd: dir}, nil
 }
 
 func (fs *FileSystem) Separator() uint8 {
@@ -40,15 +79,124 @@ func (fs *FileSystem) isDir(name string) bool {
 	return info.IsDir()
 }
 
+// Exists reports whether the named file exists. It does not follow a final
+// symbolic link, so a dangling link is reported as existing. Any error,
+// including permission errors that leave the answer unknown, is reported as
+// false.
+func (fs *FileSystem) Exists(name string) bool {
+	path, err := fs.fixPathErr("lstat", name)
+	if err != nil {
+		return false
+	}
+	_, err = os.Lstat(path)
+	return err == nil
+}
+
+// IsDir reports whether the named file is a directory, following symbolic
+// links. Any error is reported as false.
+func (fs *FileSystem) IsDir(name string) bool {
+	path, err := fs.fixPathErr("stat", name)
+	if err != nil {
+		return false
+	}
+	return fs.isDir(path)
+}
+
+// IsRegular reports whether the named file is a regular file, following
+// symbolic links. Any error is reported as false.
+func (fs *FileSystem) IsRegular(name string) bool {
+	path, err := fs.fixPathErr("stat", name)
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return info.Mode().IsRegular()
+}
+
+// SameFile reports whether a and b name the same underlying file, following
+// symbolic links. Hard links, differently spelled paths and, on Windows,
+// differences in case or drive letter all compare equal. An error is returned
+// if either path cannot be stat'd.
+func (fs *FileSystem) SameFile(a, b string) (bool, error) {
+	apath, err := fs.fixPathErr("stat", a)
+	if err != nil {
+		return false, err
+	}
+	bpath, err := fs.fixPathErr("stat", b)
+	if err != nil {
+		return false, err
+	}
+	ai, err := os.Stat(apath)
+	if err != nil {
+		return false, err
+	}
+	bi, err := os.Stat(bpath)
+	if err != nil {
+		return false, err
+	}
+	return os.SameFile(ai, bi), nil
+}
+
 func (fs *FileSystem) fixPath(name string) string {
+	name = ToNative(name)
 	if !filepath.IsAbs(name) {
+		// On Windows a rooted path without a drive, such as `\foo`, is
+		// relative to the volume of the working directory: its drive, or
+		// its share when the working directory is a UNC path.
+		if len(name) > 0 && os.IsPathSeparator(name[0]) {
+			return filepath.VolumeName(fs.cwd) + name
+		}
+		// A drive-relative path, such as `C:foo`, is relative to the
+		// working directory if that is on the same drive and to the
+		// process's current directory of the drive otherwise.
+		if vol := filepath.VolumeName(name); vol != "" {
+			if strings.EqualFold(vol, filepath.VolumeName(fs.cwd)) {
+				return filepath.Join(fs.cwd, name[len(vol):])
+			}
+			if abs, err := filepath.Abs(name); err == nil {
+				return abs
+			}
+			return name
+		}
 		name = filepath.Join(fs.cwd, name)
 	}
 	return name
 }
 
+// fixPathErr is fixPath for methods that report invalid names. For a name
+// that cannot refer to any file it returns an *os.PathError for op wrapping
+// ErrInvalidPath, rather than a native path that would fail with a less
+// helpful error, or name another file, further down.
+func (fs *FileSystem) fixPathErr(op, name string) (string, error) {
+	if !validPath(name) {
+		return "", &os.PathError{Op: op, Path: name, Err: ErrInvalidPath}
+	}
+	return fs.fixPath(name), nil
+}
+
+// Abs returns the cleaned, absolute absfs form of name, resolving relative
+// paths against the working directory. On Windows a path without a drive such
+// as `/foo` takes the drive of the working directory, or its UNC share, so
+// with a working directory of `//server/share/dir` it resolves to
+// `//server/share/foo`. Abs does not access the filesystem and name need not
+// exist, but a name that cannot refer to any file is rejected with
+// ErrInvalidPath.
+func (fs *FileSystem) Abs(name string) (string, error) {
+	path, err := fs.fixPathErr("abs", name)
+	if err != nil {
+		return "", err
+	}
+	return FromNative(filepath.Clean(path)), nil
+}
+
 func (fs *FileSystem) Chdir(name string) error {
-	name = fs.fixPath(name)
+	name, err := fs.fixPathErr("chdir", name)
+	if err != nil {
+		return err
+	}
 	if !fs.isDir(name) {
 		return &os.PathError{Op: "chdir", Path: name, Err: errors.New("not a directory")}
 	}
@@ -65,8 +213,11 @@ func (fs *FileSystem) TempDir() string {
 }
 
 func (fs *FileSystem) Open(name string) (absfs.File, error) {
-
-	f, err := os.Open(fs.fixPath(name))
+	path, err := fs.fixPathErr("open", name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
@@ -75,7 +226,28 @@ func (fs *FileSystem) Open(name string) (absfs.File, error) {
 }
 
 func (fs *FileSystem) Create(name string) (absfs.File, error) {
-	f, err := os.Create(fs.fixPath(name))
+	path, err := fs.fixPathErr("open", name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &File{fs, f}, nil
+}
+
+// CreateMode is Create with the mode perm (before umask) instead of 0666.
+// The mode is set by the same open call that creates the file, so, unlike
+// Create followed by Chmod, a file holding secrets made with 0600 is never
+// accessible to others. An existing file is truncated and keeps its mode.
+func (fs *FileSystem) CreateMode(name string, perm os.FileMode) (absfs.File, error) {
+	path, err := fs.fixPathErr("open", name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, perm)
 	if err != nil {
 		return nil, err
 	}
@@ -83,6 +255,56 @@ func (fs *FileSystem) Create(name string) (absfs.File, error) {
 	return &File{fs, f}, nil
 }
 
+// CreateExclusive creates the named file with mode perm (before umask) and
+// returns it open for writing with created set to true. If the file already
+// exists it is opened for writing instead and created is false, letting
+// callers tell "I created it" apart from "it was already there".
+//
+// The fallback open is a separate call, so the existing file may be removed
+// or replaced in between; the fallback then fails or opens the replacement.
+// Only created == true is guaranteed to be exclusive. CreateNew fails
+// instead of opening an existing file.
+func (fs *FileSystem) CreateExclusive(name string, perm os.FileMode) (absfs.File, bool, error) {
+	path, err := fs.fixPathErr("open", name)
+	if err != nil {
+		return nil, false, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err == nil {
+		return &File{fs, f}, true, nil
+	}
+	if !os.IsExist(err) {
+		return nil, false, err
+	}
+
+	f, err = os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, false, err
+	}
+	return &File{fs, f}, false, nil
+}
+
+// CreateNew creates the named file with mode perm (before umask) and returns
+// it open for writing, failing with an error for which
+// errors.Is(err, os.ErrExist) is true if the file already exists. Creation
+// and the existence check are one O_CREATE|O_EXCL open, which makes
+// CreateNew suitable for lock files.
+//
+// The open is atomic on local filesystems and on NFS version 3 and later.
+// Older NFS servers, and some FUSE and network filesystems, emulate O_EXCL
+// with a lookup followed by a create, so two clients can both succeed.
+func (fs *FileSystem) CreateNew(name string, perm os.FileMode) (absfs.File, error) {
+	path, err := fs.fixPathErr("open", name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &File{fs, f}, nil
+}
+
 // func (fs *FileSystem) MkdirAll(name string, perm os.FileMode) error {
 // 	return os.MkdirAll(fs.fixPath(name), perm)
 // }
@@ -92,19 +314,37 @@ func (fs *FileSystem) Create(name string) (absfs.File, error) {
 // }
 
 func (fs *FileSystem) Truncate(name string, size int64) error {
-	return os.Truncate(fs.fixPath(name), size)
+	path, err := fs.fixPathErr("truncate", name)
+	if err != nil {
+		return err
+	}
+	return os.Truncate(path, size)
 }
 
 func (fs *FileSystem) Mkdir(name string, perm os.FileMode) error {
-	return os.Mkdir(fs.fixPath(name), perm)
+	path, err := fs.fixPathErr("mkdir", name)
+	if err != nil {
+		return err
+	}
+	return os.Mkdir(path, perm)
 }
 
 func (fs *FileSystem) MkdirAll(name string, perm os.FileMode) error {
-	return os.MkdirAll(fs.fixPath(name), perm)
+	path, err := fs.fixPathErr("mkdir", name)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(path, perm)
 }
 
+// OpenFile opens the named file with the given flag and perm (before umask),
+// as os.OpenFile does. flag may also include O_DIRECT.
 func (fs *FileSystem) OpenFile(name string, flag int, perm os.FileMode) (absfs.File, error) {
-	f, err := os.OpenFile(fs.fixPath(name), flag, perm)
+	path, err := fs.fixPathErr("open", name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := openFile(path, flag, perm)
 	if err != nil {
 		return nil, err
 	}
@@ -117,58 +357,309 @@ func (fs *FileSystem) OpenFile(name string, flag int, perm os.FileMode) (absfs.F
 // }
 
 func (fs *FileSystem) Remove(name string) error {
-	return os.Remove(fs.fixPath(name))
+	name, err := fs.fixPathErr("remove", name)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(name)
+	if err == nil {
+		fs.temps.remove(name)
+	}
+	return err
 }
 
 func (fs *FileSystem) Rename(oldpath, newpath string) error {
-	return os.Rename(fs.fixPath(oldpath), fs.fixPath(newpath))
+	oldpath, err := fs.fixPathErr("rename", oldpath)
+	if err != nil {
+		return err
+	}
+	newpath, err = fs.fixPathErr("rename", newpath)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(oldpath, newpath)
+	if err == nil {
+		fs.temps.remove(oldpath)
+	}
+	return err
 }
 
 func (fs *FileSystem) RemoveAll(name string) error {
-	return os.RemoveAll(fs.fixPath(name))
+	path, err := fs.fixPathErr("RemoveAll", name)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(path)
 }
 
 func (fs *FileSystem) Stat(name string) (os.FileInfo, error) {
-	return os.Stat(fs.fixPath(name))
+	path, err := fs.fixPathErr("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Stat(path)
 }
 
 //Chmod changes the mode of the named file to mode.
 func (fs *FileSystem) Chmod(name string, mode os.FileMode) error {
-	return os.Chmod(fs.fixPath(name), mode)
+	path, err := fs.fixPathErr("chmod", name)
+	if err != nil {
+		return err
+	}
+	return os.Chmod(path, mode)
 }
 
 //Chtimes changes the access and modification times of the named file
 func (fs *FileSystem) Chtimes(name string, atime time.Time, mtime time.Time) error {
-	return os.Chtimes(fs.fixPath(name), atime, mtime)
+	path, err := fs.fixPathErr("chtimes", name)
+	if err != nil {
+		return err
+	}
+	return os.Chtimes(path, atime, mtime)
 }
 
 //Chown changes the owner and group ids of the named file
 func (fs *FileSystem) Chown(name string, uid, gid int) error {
-	return os.Chown(fs.fixPath(name), uid, gid)
+	path, err := fs.fixPathErr("chown", name)
+	if err != nil {
+		return err
+	}
+	return os.Chown(path, uid, gid)
 }
 
 func (fs *FileSystem) Lstat(name string) (os.FileInfo, error) {
-	return os.Lstat(fs.fixPath(name))
+	path, err := fs.fixPathErr("lstat", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Lstat(path)
 }
 
 // ess
 
 func (fs *FileSystem) Lchown(name string, uid, gid int) error {
-	return os.Lchown(fs.fixPath(name), uid, gid)
+	path, err := fs.fixPathErr("lchown", name)
+	if err != nil {
+		return err
+	}
+	return os.Lchown(path, uid, gid)
 }
 
 func (fs *FileSystem) Readlink(name string) (string, error) {
-	return os.Readlink(fs.fixPath(name))
+	path, err := fs.fixPathErr("readlink", name)
+	if err != nil {
+		return "", err
+	}
+	return os.Readlink(path)
+}
+
+// ReadlinkAbs returns the target of the named symbolic link as a clean,
+// absolute absfs path. A relative target is resolved against the directory
+// containing the link. The target itself need not exist.
+func (fs *FileSystem) ReadlinkAbs(name string) (string, error) {
+	path, err := fs.fixPathErr("readlink", name)
+	if err != nil {
+		return "", err
+	}
+	target, err := os.Readlink(path)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(target) {
+		if len(target) > 0 && os.IsPathSeparator(target[0]) {
+			target = filepath.VolumeName(path) + target
+		} else {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+	}
+	return FromNative(filepath.Clean(target)), nil
+}
+
+// EvalSymlinks returns the absfs path of name after resolving every symbolic
+// link and `..` element. All elements of the path must exist.
+func (fs *FileSystem) EvalSymlinks(name string) (string, error) {
+	path, err := fs.fixPathErr("evalsymlinks", name)
+	if err != nil {
+		return "", err
+	}
+	path, err = filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	return FromNative(path), nil
 }
 
+// Realpath returns the absolute absfs path of name with symbolic links and
+// `..` elements resolved as far as the path exists. The longest existing
+// leading part of the path is resolved as by EvalSymlinks and the rest, which
+// does not exist yet, is appended as written, so the result names where a
+// file created at name would end up. `..` elements are resolved lexically
+// before any links. If an existing element that is not a directory is
+// followed by more elements, the error from the filesystem is returned,
+// typically one reporting that it is not a directory.
+func (fs *FileSystem) Realpath(name string) (string, error) {
+	path, err := fs.fixPathErr("realpath", name)
+	if err != nil {
+		return "", err
+	}
+	head, tail := filepath.Clean(path), ""
+	for {
+		resolved, err := filepath.EvalSymlinks(head)
+		if err == nil {
+			return FromNative(filepath.Join(resolved, tail)), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		dir := filepath.Dir(head)
+		if dir == head {
+			return "", err
+		}
+		tail = filepath.Join(filepath.Base(head), tail)
+		head = dir
+	}
+}
+
+// Symlink creates newname as a symbolic link to oldname. On Windows the kind
+// of link created is selected by the SymlinkKind field.
 func (fs *FileSystem) Symlink(oldname, newname string) error {
-	return os.Symlink(fs.fixPath(oldname), fs.fixPath(newname))
+	newpath, err := fs.fixPathErr("symlink", newname)
+	if err != nil {
+		return err
+	}
+	oldpath, err := fs.fixPathErr("symlink", oldname)
+	if err != nil {
+		return err
+	}
+	return fs.symlink(oldpath, newpath)
+}
+
+// Link creates newname as a hard link to the oldname file.
+func (fs *FileSystem) Link(oldname, newname string) error {
+	oldpath, err := fs.fixPathErr("link", oldname)
+	if err != nil {
+		return err
+	}
+	newpath, err := fs.fixPathErr("link", newname)
+	if err != nil {
+		return err
+	}
+	return os.Link(oldpath, newpath)
 }
 
 func (fs *FileSystem) Walk(path string, fn func(string, os.FileInfo, error) error) error {
+	if !validPath(path) {
+		return fn(path, nil, &os.PathError{Op: "lstat", Path: path, Err: ErrInvalidPath})
+	}
 	return filepath.Walk(path, fn) //(filepath.WalkFunc)(fn))
 }
 
 func (fs *FileSystem) FastWalk(path string, fn func(string, os.FileMode) error) error {
+	if !validPath(path) {
+		return &os.PathError{Op: "lstat", Path: path, Err: ErrInvalidPath}
+	}
 	return fastwalk.Walk(path, fn)
 }
+
+// WriteFile writes data to the named file, creating it if necessary. If the
+// file does not exist it is created with perm (before umask); otherwise it is
+// truncated before writing and perm is ignored.
+func (fs *FileSystem) WriteFile(name string, data []byte, perm os.FileMode) error {
+	path, err := fs.fixPathErr("open", name)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err1 := f.Close(); err1 != nil && err == nil {
+		err = err1
+	}
+	return err
+}
+
+// AppendFile opens the named file for appending, creating it with mode perm
+// (before umask) if it does not exist. Every write goes to the end of the
+// file as it is at the time of the write, even when other processes append
+// to it too.
+//
+// Each write is one system call, so concurrent writers do not overwrite
+// each other. Writes of at most PIPE_BUF bytes (at least 512, and 4096 on
+// Linux) are not interleaved with other writers; larger writes are
+// normally kept whole by local filesystems, but that is not guaranteed.
+// On NFS appends from different clients can overwrite each other.
+func (fs *FileSystem) AppendFile(name string, perm os.FileMode) (absfs.File, error) {
+	path, err := fs.fixPathErr("open", name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &File{fs, f}, nil
+}
+
+// AppendToFile appends data to the named file in a single write, creating
+// the file with mode perm (before umask) if it does not exist. The write
+// is atomic with respect to other appenders as described for AppendFile,
+// which makes AppendToFile suitable for log lines.
+func (fs *FileSystem) AppendToFile(name string, data []byte, perm os.FileMode) error {
+	path, err := fs.fixPathErr("open", name)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, perm)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err1 := f.Close(); err1 != nil && err == nil {
+		err = err1
+	}
+	return err
+}
+
+// ReadFileInto reads the named file into buf and returns the filled slice.
+// buf is reused when it has enough capacity and grown otherwise, so callers
+// reading many similarly sized files can amortize allocations by passing the
+// previous result back in. Any existing contents of buf are overwritten.
+func (fs *FileSystem) ReadFileInto(name string, buf []byte) ([]byte, error) {
+	path, err := fs.fixPathErr("open", name)
+	if err != nil {
+		return buf[:0], err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return buf[:0], err
+	}
+	defer f.Close()
+
+	size := 0
+	if info, err := f.Stat(); err == nil {
+		if s := info.Size(); int64(int(s)) == s {
+			size = int(s)
+		}
+	}
+	size++ // one byte for the final read at EOF
+
+	if cap(buf) < size {
+		buf = make([]byte, 0, size)
+	}
+	buf = buf[:0]
+	for {
+		if len(buf) == cap(buf) {
+			buf = append(buf, 0)[:len(buf)]
+		}
+		n, err := f.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return buf, err
+		}
+	}
+}
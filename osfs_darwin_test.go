@@ -0,0 +1,64 @@
+package osfs_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestCopyFileClone(t *testing.T) {
+	fs, dir := newTempFS(t)
+
+	if err := os.WriteFile(filepath.Join(dir, "probe"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := unix.Clonefile(filepath.Join(dir, "probe"), filepath.Join(dir, "probe.clone"), 0); err != nil {
+		t.Skipf("clonefile not supported: %v", err)
+	}
+
+	if err := fs.WriteFile("src", []byte("cloned contents"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	if err := fs.Chtimes("src", mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := fs.CopyFile("dst", "src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len("cloned contents")) {
+		t.Errorf("CopyFile = %d, want %d", n, len("cloned contents"))
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "dst"))
+	if err != nil || string(data) != "cloned contents" {
+		t.Fatalf("dst = %q, %v; want %q", data, err, "cloned contents")
+	}
+	info, err := fs.Stat("dst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0640 {
+		t.Errorf("mode = %v, want %v", info.Mode().Perm(), os.FileMode(0640))
+	}
+	// A clone takes the timestamps of its source, which a buffered copy
+	// does not.
+	if !info.ModTime().Equal(mtime) {
+		t.Errorf("dst mtime = %v, want %v; the file was copied rather than cloned", info.ModTime(), mtime)
+	}
+
+	// An existing dst is overwritten by copying instead.
+	if err := fs.WriteFile("src", []byte("new"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.CopyFile("dst", "src"); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "dst")); err != nil || string(data) != "new" {
+		t.Errorf("dst after second copy = %q, %v; want %q", data, err, "new")
+	}
+}
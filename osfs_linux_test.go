@@ -0,0 +1,152 @@
+package osfs_test
+
+import (
+	"errors"
+	iofs "io/fs"
+	"os"
+	"sort"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/absfs/osfs"
+)
+
+func TestFileDeadline(t *testing.T) {
+	fs, dir := newTempFS(t)
+
+	if err := syscall.Mkfifo(dir+"/fifo", 0600); err != nil {
+		t.Skipf("mkfifo: %v", err)
+	}
+	// Opening a FIFO for reading and writing does not block on Linux.
+	f, err := fs.OpenFile("fifo", os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	d, ok := f.(interface{ SetReadDeadline(time.Time) error })
+	if !ok {
+		t.Fatalf("%T has no SetReadDeadline method", f)
+	}
+	if err := d.SetReadDeadline(time.Now().Add(20 * time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.Read(make([]byte, 1))
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("Read past deadline: err = %v, want %v", err, os.ErrDeadlineExceeded)
+	}
+
+	if err := fs.WriteFile("regular", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := fs.Open("regular")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	err = r.(interface{ SetDeadline(time.Time) error }).SetDeadline(time.Now())
+	if !errors.Is(err, os.ErrNoDeadline) {
+		t.Errorf("SetDeadline on a regular file: err = %v, want %v", err, os.ErrNoDeadline)
+	}
+}
+
+func TestFileAllocateBlocks(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	f, err := fs.Create("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	a := f.(interface{ Allocate(int64, int64) error })
+	if err := a.Allocate(0, 1<<20); err != nil {
+		if errors.Is(err, osfs.ErrNotSupported) {
+			t.Skip(err)
+		}
+		t.Fatal(err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Blocks are counted in 512-byte units.
+	if blocks := info.Sys().(*syscall.Stat_t).Blocks; blocks*512 < 1<<20 {
+		t.Errorf("%d bytes allocated, want at least %d", blocks*512, 1<<20)
+	}
+}
+
+func TestWalkOneFileSystem(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	// /dev/shm is a separate tmpfs below /dev on most Linux systems.
+	if ok, err := fs.IsMountPoint("/dev/shm"); err != nil || !ok {
+		t.Skip("/dev/shm is not a mount point")
+	}
+	tmp, err := os.MkdirTemp("/dev/shm", "osfs-walk-")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	visited := make(map[string]bool)
+	err = fs.WalkWithOptions("/dev", osfs.WalkOptions{OneFileSystem: true}, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		visited[path] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !visited["/dev/shm"] {
+		t.Error("the mount point /dev/shm was not visited")
+	}
+	if visited[tmp] {
+		t.Errorf("%s, on another filesystem, was visited", tmp)
+	}
+}
+
+func TestXattr(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	if err := fs.WriteFile("file", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.SetXattr("file", "user.osfs.test", []byte("value")); err != nil {
+		if errors.Is(err, osfs.ErrNotSupported) {
+			t.Skip(err)
+		}
+		t.Fatal(err)
+	}
+	if err := fs.SetXattr("file", "user.osfs.empty", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, err := fs.GetXattr("file", "user.osfs.test"); err != nil || string(data) != "value" {
+		t.Errorf("GetXattr = %q, %v; want %q", data, err, "value")
+	}
+	if data, err := fs.GetXattr("file", "user.osfs.empty"); err != nil || len(data) != 0 {
+		t.Errorf("GetXattr of an empty attribute = %q, %v; want empty", data, err)
+	}
+	names, err := fs.ListXattr("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(names)
+	if got, want := strings.Join(names, ","), "user.osfs.empty,user.osfs.test"; got != want {
+		t.Errorf("ListXattr = %s, want %s", got, want)
+	}
+
+	if err := fs.RemoveXattr("file", "user.osfs.test"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.GetXattr("file", "user.osfs.test"); !errors.Is(err, osfs.ErrNoXattr) {
+		t.Errorf("GetXattr after RemoveXattr: err = %v, want %v", err, osfs.ErrNoXattr)
+	}
+	if err := fs.RemoveXattr("file", "user.osfs.test"); !errors.Is(err, osfs.ErrNoXattr) {
+		t.Errorf("removing a missing attribute: err = %v, want %v", err, osfs.ErrNoXattr)
+	}
+}
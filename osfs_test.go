@@ -1,12 +1,23 @@
 package osfs_test
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	iofs "io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
 	"sync"
+	"syscall"
 	"testing"
+	"time"
+	"unicode/utf8"
+	"unsafe"
 
 	"github.com/absfs/absfs"
 	"github.com/absfs/fstesting"
@@ -247,3 +258,2543 @@ func TestOSFS(t *testing.T) {
 	}
 
 }
+
+// newTempFS returns a FileSystem whose working directory is a fresh
+// temporary directory that is removed when the test completes.
+func newTempFS(t *testing.T) (*osfs.FileSystem, string) {
+	t.Helper()
+	fs, err := osfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := fs.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	return fs, dir
+}
+
+func TestReadFileInto(t *testing.T) {
+	fs, dir := newTempFS(t)
+
+	content := []byte("Hello, world!\n")
+	if err := os.WriteFile(filepath.Join(dir, "hello.txt"), content, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	// too small, must grow
+	buf := make([]byte, 0, 4)
+	data, err := fs.ReadFileInto("hello.txt", buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(content) {
+		t.Fatalf("ReadFileInto = %q, want %q", data, content)
+	}
+
+	// large enough, must be reused
+	buf = make([]byte, 64)
+	for i := range buf {
+		buf[i] = 'x'
+	}
+	data, err = fs.ReadFileInto("hello.txt", buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(content) {
+		t.Fatalf("ReadFileInto = %q, want %q", data, content)
+	}
+	if &data[0] != &buf[0] {
+		t.Error("ReadFileInto did not reuse buf")
+	}
+
+	if _, err := fs.ReadFileInto("missing.txt", buf); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
+
+func TestAbs(t *testing.T) {
+	fs, dir := newTempFS(t)
+	wd := osfs.FromNative(dir)
+
+	tests := []struct {
+		in, out string
+	}{
+		{".", wd},
+		{"foo", wd + "/foo"},
+		{"foo/../bar/./baz", wd + "/bar/baz"},
+		{wd + "/foo/..", wd},
+	}
+	for _, test := range tests {
+		got, err := fs.Abs(test.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.out {
+			t.Errorf("Abs(%q) = %q, want %q", test.in, got, test.out)
+		}
+	}
+
+	if _, err := fs.Abs("missing"); err != nil {
+		t.Errorf("Abs of a missing path: %v", err)
+	}
+}
+
+func TestIsEmpty(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	if err := fs.Mkdir("dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	empty, err := fs.IsEmpty("dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !empty {
+		t.Error("new directory reported as not empty")
+	}
+
+	f, err := fs.Create("dir/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	empty, err = fs.IsEmpty("dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if empty {
+		t.Error("directory with a file reported as empty")
+	}
+
+	if _, err := fs.IsEmpty("missing"); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestLinkCount(t *testing.T) {
+	fs, dir := newTempFS(t)
+
+	f, err := fs.Create("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	n, err := fs.LinkCount("file")
+	if errors.Is(err, osfs.ErrNotSupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("LinkCount = %d, want 1", n)
+	}
+
+	if err := os.Link(filepath.Join(dir, "file"), filepath.Join(dir, "link")); err != nil {
+		t.Skip(err)
+	}
+	n, err = fs.LinkCount("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("LinkCount = %d, want 2", n)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	if err := fs.WriteFile("file.txt", []byte("Hello, world!\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile("file.txt", []byte("Hi\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	data, err := fs.ReadFileInto("file.txt", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "Hi\n" {
+		t.Errorf("read %q, want %q", data, "Hi\n")
+	}
+
+	info, err := fs.Stat("file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("mode %v, want %v; perm must only apply on creation", info.Mode().Perm(), os.FileMode(0600))
+	}
+
+	if err := fs.WriteFile("missing/file.txt", nil, 0600); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
+
+func TestFindHardlinks(t *testing.T) {
+	fs, dir := newTempFS(t)
+
+	for _, name := range []string{"a", "sub/d"} {
+		if err := fs.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := fs.WriteFile(name, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"b", "sub/c"} {
+		if err := os.Link(filepath.Join(dir, "a"), filepath.Join(dir, name)); err != nil {
+			t.Skip(err)
+		}
+	}
+
+	links, err := fs.FindHardlinks(".", "sub/c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wd := osfs.FromNative(dir)
+	want := []string{wd + "/a", wd + "/b", wd + "/sub/c"}
+	if strings.Join(links, ",") != strings.Join(want, ",") {
+		t.Errorf("FindHardlinks = %q, want %q", links, want)
+	}
+
+	if _, err := fs.FindHardlinks(".", "sub"); err == nil {
+		t.Error("expected an error for a directory target")
+	}
+}
+
+func TestLink(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	if err := fs.WriteFile("file", []byte("original"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Link("file", "link"); err != nil {
+		t.Skipf("hard links not supported: %v", err)
+	}
+
+	f, err := fs.OpenFile("link", os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("changed"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := fs.ReadFileInto("file", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "changed" {
+		t.Errorf("read %q through the original name, want %q", data, "changed")
+	}
+
+	if err := fs.Link("missing", "other"); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
+
+func TestMaxNameLength(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	n, err := fs.MaxNameLength(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n < 8 {
+		t.Errorf("MaxNameLength = %d, want a plausible limit", n)
+	}
+
+	if _, err := fs.MaxNameLength("missing"); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestEvalSymlinks(t *testing.T) {
+	fs, dir := newTempFS(t)
+
+	if err := fs.Mkdir("dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile("dir/real", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Symlink("dir/real", "link1"); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := fs.Symlink("link1", "link2"); err != nil {
+		t.Fatal(err)
+	}
+
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := osfs.FromNative(real) + "/dir/real"
+
+	for _, name := range []string{"link2", "dir/../link1", "dir/real"} {
+		got, err := fs.EvalSymlinks(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("EvalSymlinks(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	if _, err := fs.EvalSymlinks("dir/missing"); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
+
+func TestTruncateName(t *testing.T) {
+	tests := []struct {
+		name string
+		max  int
+		out  string
+	}{
+		{"short.txt", 255, "short.txt"},
+		{"verylongname.txt", 12, "verylong.txt"},
+		{"verylongname", 8, "verylong"},
+		{"very long. name.txt", 14, "very long.txt"},
+		{"archive.tar.gz", 10, "archive.gz"},
+		{"héllo wörld.txt", 10, "héllo.txt"},
+		{"日本語のファイル.md", 12, "日本語.md"},
+		{".bashrc_extra", 7, ".bashrc"},
+		{"name.verylongextension", 8, "name.ver"},
+		{"anything", 0, ""},
+	}
+	for _, test := range tests {
+		got := osfs.TruncateName(test.name, test.max)
+		if got != test.out {
+			t.Errorf("TruncateName(%q, %d) = %q, want %q", test.name, test.max, got, test.out)
+		}
+		if len(got) > test.max || !utf8.ValidString(got) {
+			t.Errorf("TruncateName(%q, %d) = %q is not a valid result", test.name, test.max, got)
+		}
+	}
+}
+
+func TestCopyMetadata(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	if err := fs.WriteFile("src", []byte("source"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile("dst", []byte("destination"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := fs.Chtimes("src", mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	what := osfs.MetaMode | osfs.MetaTimes
+	if runtime.GOOS != "windows" {
+		what |= osfs.MetaOwner
+	}
+	if err := fs.CopyMetadata("src", "dst", what); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := fs.Stat("src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst, err := fs.Stat("dst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dst.Mode() != src.Mode() {
+		t.Errorf("mode %v, want %v", dst.Mode(), src.Mode())
+	}
+	if !dst.ModTime().Equal(mtime) {
+		t.Errorf("mtime %v, want %v", dst.ModTime(), mtime)
+	}
+	if dst.Size() != int64(len("destination")) {
+		t.Errorf("contents changed, size %d", dst.Size())
+	}
+
+	err = fs.CopyMetadata("src", "missing", osfs.MetaMode|osfs.MetaTimes)
+	var errs osfs.MultiError
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Errorf("expected both failures to be collected, got %v", err)
+	}
+}
+
+func TestCopyMetadataXattrs(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	for _, name := range []string{"src", "dst"} {
+		if err := fs.WriteFile(name, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := fs.SetXattr("src", "user.osfs.copied", []byte("value"))
+	if errors.Is(err, osfs.ErrNotSupported) {
+		// CopyMetadata reports the missing support rather than ignoring it.
+		err := fs.CopyMetadata("src", "dst", osfs.MetaXattrs)
+		if !errors.Is(err, osfs.ErrNotSupported) {
+			t.Errorf("CopyMetadata(MetaXattrs) = %v, want ErrNotSupported", err)
+		}
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.SetXattr("dst", "user.osfs.kept", []byte("kept")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fs.CopyMetadata("src", "dst", osfs.MetaXattrs); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := fs.GetXattr("dst", "user.osfs.copied"); err != nil || string(data) != "value" {
+		t.Errorf("GetXattr(dst) = %q, %v; want %q", data, err, "value")
+	}
+	if data, err := fs.GetXattr("dst", "user.osfs.kept"); err != nil || string(data) != "kept" {
+		t.Errorf("attribute only on dst: GetXattr = %q, %v; want %q", data, err, "kept")
+	}
+}
+
+func TestCopyMetadataFlags(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	for _, name := range []string{"src", "dst"} {
+		if err := fs.WriteFile(name, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		name := name
+		t.Cleanup(func() { fs.Chflags(name, 0) })
+	}
+	err := fs.Chflags("src", osfs.FlagUserImmutable)
+	if errors.Is(err, osfs.ErrNotSupported) {
+		err := fs.CopyMetadata("src", "dst", osfs.MetaFlags)
+		var errs osfs.MultiError
+		if !errors.As(err, &errs) || len(errs) != 1 || !errors.Is(errs[0], osfs.ErrNotSupported) {
+			t.Errorf("CopyMetadata(MetaFlags) = %v, want ErrNotSupported", err)
+		}
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Flags are applied after the other fields, which the immutable flag
+	// would otherwise block.
+	if err := fs.CopyMetadata("src", "dst", osfs.MetaMode|osfs.MetaTimes|osfs.MetaFlags); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile("dst", []byte("x"), 0644); err == nil {
+		t.Error("wrote to dst, which should have been made immutable")
+	}
+}
+
+func TestCreateExclusive(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	f, created, err := fs.CreateExclusive("file", 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created {
+		t.Error("first CreateExclusive reported created == false")
+	}
+	if _, err := f.WriteString("first"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	f, created, err = fs.CreateExclusive("file", 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created {
+		t.Error("second CreateExclusive reported created == true")
+	}
+	if _, err := f.WriteString("F"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	data, err := fs.ReadFileInto("file", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "First" {
+		t.Errorf("read %q, want %q; the existing file must not be truncated", data, "First")
+	}
+
+	if _, _, err := fs.CreateExclusive("missing/file", 0644); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
+
+func TestCreateNew(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	f, err := fs.CreateNew("lock", 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("pid"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	if f, err := fs.CreateNew("lock", 0644); !errors.Is(err, os.ErrExist) {
+		if err == nil {
+			f.Close()
+		}
+		t.Errorf("second CreateNew = %v, want ErrExist", err)
+	}
+	data, err := fs.ReadFileInto("lock", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "pid" {
+		t.Errorf("read %q, want %q; the existing file must be left alone", data, "pid")
+	}
+}
+
+func TestListDirs(t *testing.T) {
+	fs, dir := newTempFS(t)
+	wd := osfs.FromNative(dir)
+
+	for _, name := range []string{"a/b/c", "a-c", "d"} {
+		if err := fs.MkdirAll(name, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := fs.WriteFile("a/file", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Symlink("a", "link"); err != nil {
+		t.Logf("symlinks not supported: %v", err)
+	}
+
+	tests := []struct {
+		depth int
+		dirs  []string
+	}{
+		{-1, []string{"a", "a-c", "a/b", "a/b/c", "d"}},
+		{0, nil},
+		{1, []string{"a", "a-c", "d"}},
+		{2, []string{"a", "a-c", "a/b", "d"}},
+	}
+	for _, test := range tests {
+		dirs, err := fs.ListDirsDepth(".", test.depth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var want []string
+		for _, d := range test.dirs {
+			want = append(want, wd+"/"+d)
+		}
+		if strings.Join(dirs, ",") != strings.Join(want, ",") {
+			t.Errorf("ListDirsDepth(%d) = %q, want %q", test.depth, dirs, want)
+		}
+	}
+
+	if _, err := fs.ListDirs("missing"); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
+
+func TestExistsIsDirIsRegular(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	if err := fs.Mkdir("dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile("file", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	type existsTest struct {
+		name                     string
+		exists, isDir, isRegular bool
+	}
+	tests := []existsTest{
+		{"dir", true, true, false},
+		{"file", true, false, true},
+		{"missing", false, false, false},
+		{"file/child", false, false, false},
+	}
+	if err := fs.Symlink("missing", "dangling"); err == nil {
+		tests = append(tests, existsTest{"dangling", true, false, false})
+	} else {
+		t.Logf("symlinks not supported: %v", err)
+	}
+
+	for _, test := range tests {
+		if got := fs.Exists(test.name); got != test.exists {
+			t.Errorf("Exists(%q) = %v, want %v", test.name, got, test.exists)
+		}
+		if got := fs.IsDir(test.name); got != test.isDir {
+			t.Errorf("IsDir(%q) = %v, want %v", test.name, got, test.isDir)
+		}
+		if got := fs.IsRegular(test.name); got != test.isRegular {
+			t.Errorf("IsRegular(%q) = %v, want %v", test.name, got, test.isRegular)
+		}
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	files := map[string]string{
+		"src/a.txt":     "top",
+		"src/x/a.txt":   "x",
+		"src/x/y/a.txt": "y",
+		"src/x/b.txt":   "b",
+	}
+	for name, data := range files {
+		if err := fs.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := fs.WriteFile(name, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	read := func(name string) string {
+		buf, err := fs.ReadFileInto(name, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(buf)
+	}
+
+	if err := fs.Flatten("src", "rename", osfs.ConflictRename); err != nil {
+		t.Fatal(err)
+	}
+	names, err := fs.Glob("rename/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 4 || read("rename/b.txt") != "b" {
+		t.Errorf("ConflictRename produced %q", names)
+	}
+	for _, name := range []string{"rename/a_1.txt", "rename/a_2.txt"} {
+		if !fs.Exists(name) {
+			t.Errorf("%s is missing", name)
+		}
+	}
+
+	if err := fs.Flatten("src", "skip", osfs.ConflictSkip); err != nil {
+		t.Fatal(err)
+	}
+	if got := read("skip/a.txt"); got != "top" {
+		t.Errorf("ConflictSkip kept %q, want %q", got, "top")
+	}
+
+	if err := fs.Flatten("src", "overwrite", osfs.ConflictOverwrite); err != nil {
+		t.Fatal(err)
+	}
+	if got := read("overwrite/a.txt"); got != "y" {
+		t.Errorf("ConflictOverwrite kept %q, want %q", got, "y")
+	}
+
+	err = fs.FlattenWithOptions("src", "src/flat", osfs.FlattenOptions{Move: true, EncodePath: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		flat := "src/flat/" + strings.ReplaceAll(strings.TrimPrefix(name, "src/"), "/", "_")
+		if got := read(flat); got != data {
+			t.Errorf("%s = %q, want %q", flat, got, data)
+		}
+		if fs.Exists(name) {
+			t.Errorf("%s was not moved", name)
+		}
+	}
+}
+
+func TestFlattenInPlace(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	files := map[string]string{
+		"tree/a.txt":     "top",
+		"tree/x/a.txt":   "x",
+		"tree/x/y/b.txt": "b",
+	}
+	for name, data := range files {
+		if err := fs.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := fs.WriteFile(name, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := fs.FlattenWithOptions("tree", "tree", osfs.FlattenOptions{Move: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"tree/a.txt":   "top",
+		"tree/a_1.txt": "x",
+		"tree/b.txt":   "b",
+	}
+	for name, data := range want {
+		buf, err := fs.ReadFileInto(name, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(buf) != data {
+			t.Errorf("%s = %q, want %q", name, buf, data)
+		}
+	}
+	for _, name := range []string{"tree/x/a.txt", "tree/x/y/b.txt", "tree/a_2.txt"} {
+		if fs.Exists(name) {
+			t.Errorf("%s exists after flattening in place", name)
+		}
+	}
+}
+
+func TestReadFileContext(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	data := make([]byte, 3<<20+17)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	if err := fs.WriteFile("big", data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := fs.ReadFileContext(context.Background(), "big")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("ReadFileContext returned %d bytes, want %d", len(got), len(data))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := fs.ReadFileContext(ctx, "big"); err != context.Canceled {
+		t.Errorf("ReadFileContext with a cancelled context: err = %v, want %v", err, context.Canceled)
+	}
+	if _, err := fs.OpenFileContext(ctx, "big", os.O_RDONLY, 0); err != context.Canceled {
+		t.Errorf("OpenFileContext with a cancelled context: err = %v, want %v", err, context.Canceled)
+	}
+
+	f, err := fs.OpenFileContext(context.Background(), "big", os.O_RDONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	if _, err := fs.ReadFileContext(context.Background(), "missing"); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
+
+func TestCopyFile(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), 1<<16)
+	if err := fs.WriteFile("src", data, 0640); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile("dst", []byte("previous contents that are longer"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dst := range []string{"new", "dst"} {
+		n, err := fs.CopyFile(dst, "src")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != int64(len(data)) {
+			t.Errorf("CopyFile(%q) copied %d bytes, want %d", dst, n, len(data))
+		}
+		got, err := fs.ReadFileInto(dst, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("%s differs from src", dst)
+		}
+		if runtime.GOOS != "windows" {
+			info, err := fs.Stat(dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != 0640 {
+				t.Errorf("%s has mode %v, want %v", dst, info.Mode().Perm(), os.FileMode(0640))
+			}
+		}
+	}
+
+	if _, err := fs.CopyFile("src", "src"); err == nil {
+		t.Error("copying a file onto itself succeeded")
+	}
+	if got, _ := fs.ReadFileInto("src", nil); !bytes.Equal(got, data) {
+		t.Error("copying a file onto itself clobbered it")
+	}
+	if _, err := fs.CopyFile("x", "missing"); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
+
+func TestSameFile(t *testing.T) {
+	fs, dir := newTempFS(t)
+
+	if err := fs.Mkdir("sub", 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a", "b"} {
+		if err := fs.WriteFile(name, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type sameTest struct {
+		a, b string
+		same bool
+	}
+	tests := []sameTest{
+		{"a", "a", true},
+		{"a", "b", false},
+		{"a", "sub/../a", true},
+		{"a", filepath.Join(dir, "a"), true},
+		{"sub", ".", false},
+	}
+	if err := fs.Link("a", "hard"); err == nil {
+		tests = append(tests, sameTest{"a", "hard", true})
+	}
+	if err := fs.Symlink("a", "soft"); err == nil {
+		tests = append(tests, sameTest{"soft", "a", true})
+	}
+
+	for _, test := range tests {
+		same, err := fs.SameFile(test.a, test.b)
+		if err != nil {
+			t.Errorf("SameFile(%q, %q): %v", test.a, test.b, err)
+			continue
+		}
+		if same != test.same {
+			t.Errorf("SameFile(%q, %q) = %v, want %v", test.a, test.b, same, test.same)
+		}
+	}
+
+	if _, err := fs.SameFile("a", "missing"); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
+
+func TestWriteReader(t *testing.T) {
+	fs, _ := newTempFS(t)
+	fs.BufferSize = 7
+
+	data := []byte(strings.Repeat("pooled buffers ", 100))
+	n, err := fs.WriteReader("file", bytes.NewReader(data), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(data)) {
+		t.Errorf("WriteReader wrote %d bytes, want %d", n, len(data))
+	}
+	got, err := fs.ReadFileInto("file", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("file holds %q, want %q", got, data)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("file%d", i)
+			if _, err := fs.WriteReader(name, bytes.NewReader(data[i:]), 0644); err != nil {
+				t.Error(err)
+				return
+			}
+			if _, err := fs.CopyFile(name+".copy", name); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	for i := 0; i < 8; i++ {
+		got, err := fs.ReadFileInto(fmt.Sprintf("file%d.copy", i), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, data[i:]) {
+			t.Errorf("file%d.copy differs", i)
+		}
+	}
+
+	// With BufferSize unset the buffer follows the destination's block
+	// size, which a large write spans several times.
+	fs.BufferSize = 0
+	big := bytes.Repeat(data, 200)
+	if _, err := fs.WriteReader("big", bytes.NewReader(big), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.CopyFile("big.copy", "big"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := fs.ReadFileInto("big.copy", nil); err != nil || !bytes.Equal(got, big) {
+		t.Errorf("big.copy differs: %v", err)
+	}
+}
+
+// BenchmarkWriteReader writes 1000 small files per iteration through the
+// FileSystem's buffer pool; compare its allocations with
+// BenchmarkWriteReaderUnpooled.
+func BenchmarkWriteReader(b *testing.B) {
+	benchmarkWriteFiles(b, func(fs *osfs.FileSystem, name string, r io.Reader) error {
+		_, err := fs.WriteReader(name, r, 0644)
+		return err
+	})
+}
+
+func BenchmarkWriteReaderUnpooled(b *testing.B) {
+	benchmarkWriteFiles(b, func(fs *osfs.FileSystem, name string, r io.Reader) error {
+		f, err := fs.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(struct{ io.Writer }{f}, struct{ io.Reader }{r})
+		if err1 := f.Close(); err == nil {
+			err = err1
+		}
+		return err
+	})
+}
+
+func benchmarkWriteFiles(b *testing.B, write func(*osfs.FileSystem, string, io.Reader) error) {
+	fs, err := osfs.NewFS()
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := fs.Chdir(b.TempDir()); err != nil {
+		b.Fatal(err)
+	}
+	names := make([]string, 1000)
+	for i := range names {
+		names[i] = fmt.Sprintf("file%04d", i)
+	}
+	data := bytes.Repeat([]byte("x"), 512)
+	r := bytes.NewReader(data)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, name := range names {
+			r.Reset(data)
+			if err := write(fs, name, r); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestOpenLocked(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	if err := fs.WriteFile("file", []byte("contents"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := fs.OpenLocked("file", os.O_RDWR, 0, true)
+	if errors.Is(err, osfs.ErrNotSupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	locked := make(chan error)
+	go func() {
+		g, err := fs.OpenLocked("file", os.O_RDWR|os.O_TRUNC, 0, true)
+		if err == nil {
+			err = g.Close()
+		}
+		locked <- err
+	}()
+
+	select {
+	case err := <-locked:
+		t.Fatalf("second exclusive lock granted while the first was held: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if info, err := fs.Stat("file"); err != nil || info.Size() != 8 {
+		t.Errorf("file was truncated before the lock was granted")
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-locked; err != nil {
+		t.Fatal(err)
+	}
+	if info, err := fs.Stat("file"); err != nil || info.Size() != 0 {
+		t.Errorf("O_TRUNC did not truncate the file once locked")
+	}
+}
+
+func TestStatfs(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	if err := fs.WriteFile("file", []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{".", "file"} {
+		u, err := fs.Statfs(name)
+		if errors.Is(err, osfs.ErrNotSupported) {
+			t.Skip(err)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.Total == 0 || u.Free > u.Total || u.Available > u.Free {
+			t.Errorf("Statfs(%q) = %+v", name, u)
+		}
+	}
+
+	if _, err := fs.Statfs("missing"); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
+
+func TestAccess(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	if err := fs.WriteFile("file", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Access("file", osfs.AccessExists); err != nil {
+		t.Errorf("Access(file, AccessExists): %v", err)
+	}
+	if err := fs.Access("missing", osfs.AccessExists); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+	err := fs.Access("file", osfs.AccessRead|osfs.AccessWrite)
+	if errors.Is(err, osfs.ErrNotSupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Errorf("Access(file, AccessRead|AccessWrite): %v", err)
+	}
+	if err := fs.Access(".", osfs.AccessRead|osfs.AccessWrite); err != nil {
+		t.Errorf("Access(., AccessRead|AccessWrite): %v", err)
+	}
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	if err := fs.Access("file", osfs.AccessExecute); err == nil {
+		t.Error("file without execute bits is executable")
+	}
+	if os.Geteuid() == 0 {
+		return // root may write to anything
+	}
+	if err := fs.Chmod("file", 0444); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Access("file", osfs.AccessWrite); !os.IsPermission(err) {
+		t.Errorf("Access on a read-only file: err = %v, want a permission error", err)
+	}
+}
+
+func TestVerifyReadable(t *testing.T) {
+	fs, _ := newTempFS(t)
+	wd, _ := fs.Getwd()
+
+	if err := fs.MkdirAll("root/sub", 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"root/ok", "root/sub/ok", "root/sub/locked"} {
+		if err := fs.WriteFile(name, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bad, err := fs.VerifyReadable("root")
+	if err != nil || len(bad) != 0 {
+		t.Fatalf("VerifyReadable = %v, %v on a readable tree", bad, err)
+	}
+
+	var want []osfs.VerifyFailure
+	if err := fs.Symlink("missing", "root/dangling"); err == nil {
+		want = append(want, osfs.VerifyFailure{Path: wd + "/root/dangling"})
+	}
+	if runtime.GOOS != "windows" && os.Geteuid() != 0 {
+		if err := fs.Chmod("root/sub/locked", 0); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, osfs.VerifyFailure{Path: wd + "/root/sub/locked", Permission: true})
+	}
+
+	bad, err = fs.VerifyReadable("root")
+	if len(bad) != len(want) {
+		t.Fatalf("VerifyReadable = %v, want %v", bad, want)
+	}
+	for i, w := range want {
+		g := bad[i]
+		if g.Path != w.Path || g.Permission != w.Permission || g.Err == nil {
+			t.Errorf("failure %d = %+v, want %+v", i, g, w)
+		}
+		if g.Permission != os.IsPermission(g.Err) {
+			t.Errorf("failure %d has Permission %v for %v", i, g.Permission, g.Err)
+		}
+	}
+	if len(want) == 0 {
+		return
+	}
+	var errs osfs.MultiError
+	if !errors.As(err, &errs) || len(errs) != len(want) {
+		t.Fatalf("VerifyReadable error = %v, want a MultiError of %d", err, len(want))
+	}
+	if !os.IsNotExist(errs[0]) {
+		t.Errorf("dangling symlink reported as %v", errs[0])
+	}
+
+	if _, err := fs.VerifyReadable("missing"); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
+
+func TestReadDirDots(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	if err := fs.Mkdir("dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"dir/b", "dir/-a"} {
+		if err := fs.WriteFile(name, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := fs.ReadDirDots("dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if got := strings.Join(names, ","); got != ".,..,-a,b" {
+		t.Fatalf("ReadDirDots = %s, want .,..,-a,b", got)
+	}
+
+	dir, _ := fs.Stat("dir")
+	parent, _ := fs.Stat(".")
+	for i, want := range []os.FileInfo{dir, parent} {
+		e := entries[i]
+		if !e.IsDir() || e.Type() != os.ModeDir {
+			t.Errorf("%s is not a directory", e.Name())
+		}
+		info, err := e.Info()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !os.SameFile(info, want) {
+			t.Errorf("Info of %s describes the wrong directory", e.Name())
+		}
+	}
+
+	if _, err := fs.ReadDirDots("missing"); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
+
+func TestWalkDir(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	for _, dir := range []string{"root/a/deep", "root/b", "root/skip/sub"} {
+		if err := fs.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"root/a/deep/f", "root/b/g", "root/skip/sub/h", "root/z"} {
+		if err := fs.WriteFile(name, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var visited []string
+	err := fs.WalkDir("root", func(path string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		visited = append(visited, path)
+		if d.Name() == "skip" {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "root,root/a,root/a/deep,root/a/deep/f,root/b,root/b/g,root/skip,root/z"
+	if got := strings.Join(visited, ","); got != want {
+		t.Errorf("WalkDir visited\n%s\nwant\n%s", got, want)
+	}
+
+	wd, _ := fs.Getwd()
+	abs := osfs.FromNative(wd) + "/root/b"
+	visited = nil
+	err = fs.WalkDir(abs, func(path string, d iofs.DirEntry, err error) error {
+		visited = append(visited, path)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(visited, ","), abs+","+abs+"/g"; got != want {
+		t.Errorf("WalkDir visited %s, want %s", got, want)
+	}
+
+	stop := errors.New("stop")
+	err = fs.WalkDir("root", func(path string, d iofs.DirEntry, err error) error {
+		if path == "root/b" {
+			return stop
+		}
+		return err
+	})
+	if err != stop {
+		t.Errorf("WalkDir returned %v, want the callback's error", err)
+	}
+}
+
+func TestRangeDir(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	const n = 1000
+	if err := fs.Mkdir("dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		if err := fs.WriteFile(fmt.Sprintf("dir/%d", i), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	seen := make(map[string]bool)
+	err := fs.RangeDir("dir", func(e os.DirEntry) error {
+		if seen[e.Name()] {
+			t.Errorf("%s seen twice", e.Name())
+		}
+		seen[e.Name()] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != n {
+		t.Errorf("RangeDir yielded %d entries, want %d", len(seen), n)
+	}
+
+	count := 0
+	err = fs.RangeDir("dir", func(e os.DirEntry) error {
+		count++
+		if count == 10 {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil || count != 10 {
+		t.Errorf("stopping with SkipDir: %d entries, err %v", count, err)
+	}
+
+	stop := errors.New("stop")
+	if err := fs.RangeDir("dir", func(os.DirEntry) error { return stop }); err != stop {
+		t.Errorf("RangeDir returned %v, want the callback's error", err)
+	}
+	if err := fs.RangeDir("missing", func(os.DirEntry) error { return nil }); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
+
+func TestReadDirUnsorted(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	want := []string{"a", "b", "c", "d"}
+	for _, name := range want {
+		if err := fs.WriteFile(name, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := fs.ReadDirUnsorted(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("ReadDirUnsorted = %q, want %q in any order", names, want)
+	}
+	if _, err := fs.ReadDirUnsorted("missing"); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
+
+func BenchmarkReadDir(b *testing.B) {
+	fs, dir := newBenchDir(b, 10000)
+	b.Run("Sorted", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := os.ReadDir(dir); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Unsorted", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := fs.ReadDirUnsorted(dir); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("FilePaged", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			f, err := fs.Open(dir)
+			if err != nil {
+				b.Fatal(err)
+			}
+			d := f.(interface {
+				ReadDir(int) ([]os.DirEntry, error)
+			})
+			for {
+				if _, err := d.ReadDir(256); err == io.EOF {
+					break
+				} else if err != nil {
+					b.Fatal(err)
+				}
+			}
+			f.Close()
+		}
+	})
+}
+
+// newBenchDir returns a FileSystem and a directory holding n empty files.
+func newBenchDir(b *testing.B, n int) (*osfs.FileSystem, string) {
+	b.Helper()
+	fs, err := osfs.NewFS()
+	if err != nil {
+		b.Fatal(err)
+	}
+	dir := b.TempDir()
+	for i := 0; i < n; i++ {
+		f, err := os.Create(filepath.Join(dir, fmt.Sprintf("file%05d", (i*7919)%n)))
+		if err != nil {
+			b.Fatal(err)
+		}
+		f.Close()
+	}
+	return fs, dir
+}
+
+func TestLchtimes(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	if err := fs.Symlink("missing", "dangling"); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	mtime := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	err := fs.Lchtimes("dangling", mtime, mtime)
+	if errors.Is(err, osfs.ErrNotSupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := fs.Lstat("dangling")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(mtime) {
+		t.Errorf("link mtime = %v, want %v", info.ModTime(), mtime)
+	}
+
+	// A zero time is left unchanged, as with Chtimes.
+	if err := fs.Lchtimes("dangling", time.Now(), time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if info, err = fs.Lstat("dangling"); err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(mtime) {
+		t.Errorf("link mtime = %v after a zero mtime, want %v", info.ModTime(), mtime)
+	}
+
+	if err := fs.Lchtimes("missing", mtime, mtime); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
+
+func TestReadlinkAbs(t *testing.T) {
+	fs, dir := newTempFS(t)
+	wd := osfs.FromNative(dir)
+
+	if err := fs.MkdirAll("a/b", 0755); err != nil {
+		t.Fatal(err)
+	}
+	// Create the links directly: FileSystem.Symlink stores absolute targets.
+	links := map[string]string{
+		"a/b/rel":      filepath.FromSlash("../target"),
+		"a/b/dot":      ".",
+		"a/b/absolute": filepath.Join(dir, "x", "..", "elsewhere"),
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, filepath.Join(dir, filepath.FromSlash(link))); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+
+	tests := map[string]string{
+		"a/b/rel":      wd + "/a/target",
+		"a/b/dot":      wd + "/a/b",
+		"a/b/absolute": wd + "/elsewhere",
+	}
+	for link, want := range tests {
+		got, err := fs.ReadlinkAbs(link)
+		if err != nil {
+			t.Errorf("ReadlinkAbs(%q): %v", link, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ReadlinkAbs(%q) = %q, want %q", link, got, want)
+		}
+	}
+
+	if raw, err := fs.Readlink("a/b/rel"); err != nil || raw != links["a/b/rel"] {
+		t.Errorf("Readlink(a/b/rel) = %q, %v, want the raw target", raw, err)
+	}
+	if _, err := fs.ReadlinkAbs("a"); err == nil {
+		t.Error("ReadlinkAbs of a directory succeeded")
+	}
+}
+
+func TestFileLock(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	open := func() *osfs.File {
+		f, err := fs.OpenFile("file", os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f.(*osfs.File)
+	}
+	a, b := open(), open()
+	defer a.Close()
+
+	err := a.Lock()
+	if errors.Is(err, osfs.ErrNotSupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.TryLock(); !errors.Is(err, osfs.ErrLocked) {
+		t.Fatalf("TryLock on a locked file: err = %v, want %v", err, osfs.ErrLocked)
+	}
+	if err := a.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.TryLock(); err != nil {
+		t.Fatalf("TryLock after Unlock: %v", err)
+	}
+	if err := a.TryLock(); !errors.Is(err, osfs.ErrLocked) {
+		t.Fatalf("TryLock on a locked file: err = %v, want %v", err, osfs.ErrLocked)
+	}
+
+	// Closing the file releases its lock.
+	b.Close()
+	if err := a.TryLock(); err != nil {
+		t.Fatalf("TryLock after Close: %v", err)
+	}
+}
+
+func TestFileReadFromWriteTo(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	data := bytes.Repeat([]byte("copy fast "), 100000)
+	if err := fs.WriteFile("src", data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	copyFile := func(dst string, copy func(dst, src absfs.File) (int64, error)) {
+		t.Helper()
+		src, err := fs.Open("src")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer src.Close()
+		out, err := fs.Create(dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := copy(out, src)
+		if err1 := out.Close(); err == nil {
+			err = err1
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != int64(len(data)) {
+			t.Errorf("%s: copied %d bytes, want %d", dst, n, len(data))
+		}
+		got, err := fs.ReadFileInto(dst, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("%s differs from src", dst)
+		}
+	}
+
+	copyFile("readfrom", func(dst, src absfs.File) (int64, error) {
+		return dst.(io.ReaderFrom).ReadFrom(src)
+	})
+	copyFile("writeto", func(dst, src absfs.File) (int64, error) {
+		return src.(io.WriterTo).WriteTo(dst)
+	})
+	copyFile("buffer", func(dst, src absfs.File) (int64, error) {
+		var buf bytes.Buffer
+		if _, err := src.(io.WriterTo).WriteTo(&buf); err != nil {
+			return 0, err
+		}
+		return dst.(io.ReaderFrom).ReadFrom(&buf)
+	})
+}
+
+func BenchmarkFileCopy(b *testing.B) {
+	fs, err := osfs.NewFS()
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := fs.Chdir(b.TempDir()); err != nil {
+		b.Fatal(err)
+	}
+	data := bytes.Repeat([]byte("x"), 8<<20)
+	if err := fs.WriteFile("src", data, 0644); err != nil {
+		b.Fatal(err)
+	}
+
+	bench := func(b *testing.B, copy func(dst, src absfs.File) error) {
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			src, err := fs.Open("src")
+			if err != nil {
+				b.Fatal(err)
+			}
+			dst, err := fs.Create("dst")
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err := copy(dst, src); err != nil {
+				b.Fatal(err)
+			}
+			src.Close()
+			dst.Close()
+		}
+	}
+	b.Run("ReadFrom", func(b *testing.B) {
+		bench(b, func(dst, src absfs.File) error {
+			_, err := io.Copy(dst, src)
+			return err
+		})
+	})
+	b.Run("Generic", func(b *testing.B) {
+		bench(b, func(dst, src absfs.File) error {
+			_, err := io.Copy(struct{ io.Writer }{dst}, struct{ io.Reader }{src})
+			return err
+		})
+	})
+}
+
+func TestCopyTree(t *testing.T) {
+	fs, dir := newTempFS(t)
+
+	if err := fs.MkdirAll("src/sub", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile("src/a.txt", []byte("a"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile("src/sub/b.txt", []byte("b"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := fs.Chtimes("src/a.txt", mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	links := os.Symlink("a.txt", filepath.Join(dir, "src", "link")) == nil
+	if err := fs.Chmod("src/sub", 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := osfs.CopyOptions{PreserveSymlinks: true, PreserveTimes: true}
+	if err := fs.CopyTree("src/copy", "src", opts); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{"a.txt": "a", "sub/b.txt": "b"} {
+		data, err := fs.ReadFileInto("src/copy/"+name, nil)
+		if err != nil {
+			t.Error(err)
+		} else if string(data) != want {
+			t.Errorf("%s = %q, want %q", name, data, want)
+		}
+	}
+	if fs.Exists("src/copy/copy") {
+		t.Error("destination inside the source was copied into itself")
+	}
+	if info, err := fs.Stat("src/copy/a.txt"); err != nil {
+		t.Error(err)
+	} else if !info.ModTime().Equal(mtime) {
+		t.Errorf("a.txt mtime = %v, want %v", info.ModTime(), mtime)
+	}
+	if runtime.GOOS != "windows" {
+		if info, err := fs.Stat("src/copy/sub"); err != nil {
+			t.Error(err)
+		} else if info.Mode().Perm() != 0750 {
+			t.Errorf("sub mode = %v, want %v", info.Mode().Perm(), os.FileMode(0750))
+		}
+	}
+	if links {
+		if target, err := fs.Readlink("src/copy/link"); err != nil || target != "a.txt" {
+			t.Errorf("Readlink(link) = %q, %v; want %q", target, err, "a.txt")
+		}
+	}
+
+	if err := fs.CopyTree("dst", "src/sub", osfs.CopyOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.CopyTree("dst", "src/sub", osfs.CopyOptions{}); !errors.Is(err, os.ErrExist) {
+		t.Errorf("copying over an existing file: err = %v, want %v", err, os.ErrExist)
+	}
+	if err := fs.WriteFile("src/sub/c.txt", []byte("c"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := fs.CopyTree("dst", "src/sub", osfs.CopyOptions{ContinueOnError: true})
+	var merr osfs.MultiError
+	if !errors.As(err, &merr) || len(merr) != 1 {
+		t.Errorf("ContinueOnError: err = %v, want a MultiError of 1 error", err)
+	}
+	if !fs.Exists("dst/c.txt") {
+		t.Error("ContinueOnError did not copy the remaining files")
+	}
+	if err := fs.CopyTree("dst", "src/sub", osfs.CopyOptions{Overwrite: true}); err != nil {
+		t.Errorf("Overwrite: %v", err)
+	}
+}
+
+func TestRemoveAllContext(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	mktree := func() int {
+		t.Helper()
+		n := 1 // the root
+		for _, d := range []string{"tree/a", "tree/b/c"} {
+			if err := fs.MkdirAll(d, 0755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		n += 3
+		for i := 0; i < 300; i++ {
+			name := fmt.Sprintf("tree/%c/f%d", "ab"[i%2], i)
+			if err := fs.WriteFile(name, nil, 0444); err != nil {
+				t.Fatal(err)
+			}
+			n++
+		}
+		return n
+	}
+
+	n := mktree()
+	ctx, cancel := context.WithCancel(context.Background())
+	err := fs.RemoveAllContext(ctx, "tree", func(removed int) {
+		cancel()
+	})
+	if err != context.Canceled {
+		t.Errorf("cancelled RemoveAllContext: err = %v, want %v", err, context.Canceled)
+	}
+	if !fs.Exists("tree") {
+		t.Error("cancelled RemoveAllContext removed the whole tree")
+	}
+
+	n = mktree()
+	var calls []int
+	if err := fs.RemoveAllContext(context.Background(), "tree", func(removed int) {
+		calls = append(calls, removed)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if fs.Exists("tree") {
+		t.Error("tree still exists")
+	}
+	if len(calls) != 2 || calls[0] != 256 || calls[1] != n {
+		t.Errorf("progress calls = %v, want [256 %d]", calls, n)
+	}
+
+	if err := fs.RemoveAllContext(context.Background(), "missing", nil); err != nil {
+		t.Errorf("removing a missing path: %v", err)
+	}
+}
+
+func TestMove(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	if err := fs.MkdirAll("src/sub", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile("src/sub/file", []byte("data"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Move("src", "dst"); err != nil {
+		t.Fatal(err)
+	}
+	if fs.Exists("src") || !fs.IsRegular("dst/sub/file") {
+		t.Error("Move within a device did not move the tree")
+	}
+
+	// The copy fallback only runs when the destination is on another
+	// device. /dev/shm is a separate tmpfs on most Linux systems, so moving
+	// there exercises it; where it is not, this repeats the rename case.
+	other, err := os.MkdirTemp("/dev/shm", "osfs-move-")
+	if err != nil {
+		t.Skip("no second filesystem to move across")
+	}
+	defer os.RemoveAll(other)
+
+	target := filepath.Join(other, "dst")
+	if err := fs.Move("dst", target); err != nil {
+		t.Fatal(err)
+	}
+	if fs.Exists("dst") {
+		t.Error("source still exists after a cross-device Move")
+	}
+	data, err := fs.ReadFileInto(filepath.Join(target, "sub", "file"), nil)
+	if err != nil || string(data) != "data" {
+		t.Errorf("moved file = %q, %v; want %q", data, err, "data")
+	}
+	if info, err := os.Stat(filepath.Join(target, "sub", "file")); err == nil && info.Mode().Perm() != 0640 {
+		t.Errorf("moved file mode = %v, want %v", info.Mode().Perm(), os.FileMode(0640))
+	}
+}
+
+func TestFileAllocate(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	f, err := fs.Create("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	a := f.(interface{ Allocate(int64, int64) error })
+	if err := a.Allocate(0, 1<<20); err != nil {
+		if errors.Is(err, osfs.ErrNotSupported) {
+			t.Skip(err)
+		}
+		t.Fatal(err)
+	}
+	if info, err := f.Stat(); err != nil {
+		t.Fatal(err)
+	} else if info.Size() != 1<<20 {
+		t.Errorf("size after Allocate = %d, want %d", info.Size(), 1<<20)
+	}
+
+	// Allocating inside the file must not shrink it.
+	if err := a.Allocate(0, 10); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := f.Stat(); err != nil {
+		t.Fatal(err)
+	} else if info.Size() != 1<<20 {
+		t.Errorf("size after a second Allocate = %d, want %d", info.Size(), 1<<20)
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	const nfc, nfd = "caf\u00e9", "cafe\u0301"
+	if got := fs.NormalizeName(nfd); got != nfc {
+		t.Errorf("NormalizeName(%q) = %q, want %q", nfd, got, nfc)
+	}
+
+	if err := fs.Mkdir("dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile("dir/"+nfc, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := fs.ReadDirUnsorted("dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || fs.NormalizeName(entries[0].Name()) != nfc {
+		t.Fatalf("listing %v does not round-trip to %q", entries, nfc)
+	}
+
+	// A decomposed name, as macOS may report it, is composed when
+	// NormalizeNames is set.
+	if err := fs.Remove("dir/" + entries[0].Name()); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile("dir/"+nfd, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	fs.NormalizeNames = true
+	f, err := fs.Open("dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	names, err := f.Readdirnames(-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 || names[0] != nfc {
+		t.Errorf("Readdirnames = %q, want [%q]", names, nfc)
+	}
+	entries, err = fs.ReadDirUnsorted("dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err := entries[0].Info(); err != nil {
+		t.Error(err)
+	} else if info.Name() != nfc {
+		t.Errorf("entry Info().Name() = %q, want %q", info.Name(), nfc)
+	}
+}
+
+func TestRealName(t *testing.T) {
+	fs, dir := newTempFS(t)
+
+	if err := fs.MkdirAll("Foo/Bar", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile("Foo/Bar/File.TXT", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := osfs.FromNative(filepath.Join(dir, "Foo", "Bar", "File.TXT"))
+	for _, name := range []string{"Foo/Bar/File.TXT", "foo/bar/file.txt", "FOO/./bar/../Bar/file.Txt"} {
+		got, err := fs.RealName(name)
+		if err != nil {
+			t.Errorf("RealName(%q): %v", name, err)
+		} else if got != want {
+			t.Errorf("RealName(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	if _, err := fs.RealName("foo/missing"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("RealName of a missing file: err = %v, want %v", err, os.ErrNotExist)
+	}
+}
+
+func TestIsMountPoint(t *testing.T) {
+	fs, dir := newTempFS(t)
+
+	root := filepath.VolumeName(dir) + string(filepath.Separator)
+	ok, err := fs.IsMountPoint(root)
+	if errors.Is(err, osfs.ErrNotSupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Errorf("IsMountPoint(%q) = false, want true", root)
+	}
+
+	if err := fs.Mkdir("sub", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile("file", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"sub", "file"} {
+		if ok, err := fs.IsMountPoint(name); err != nil || ok {
+			t.Errorf("IsMountPoint(%q) = %v, %v; want false", name, ok, err)
+		}
+	}
+	if _, err := fs.IsMountPoint("missing"); !os.IsNotExist(err) {
+		t.Errorf("IsMountPoint of a missing file: err = %v, want not exist", err)
+	}
+}
+
+func TestWalkWithOptions(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	for _, name := range []string{"root/a/f", "root/b/g"} {
+		if err := fs.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := fs.WriteFile(name, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var visited []string
+	opts := osfs.WalkOptions{OneFileSystem: true}
+	err := fs.WalkWithOptions("root", opts, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		visited = append(visited, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "root,root/a,root/a/f,root/b,root/b/g"
+	if got := strings.Join(visited, ","); got != want {
+		t.Errorf("WalkWithOptions visited\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestChflags(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	if err := fs.WriteFile("file", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Chflags("file", osfs.FlagNoDump); err != nil {
+		if errors.Is(err, osfs.ErrNotSupported) {
+			t.Skip(err)
+		}
+		t.Fatal(err)
+	}
+	if err := fs.Chflags("file", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Chflags("missing", 0); !os.IsNotExist(err) {
+		t.Errorf("Chflags of a missing file: err = %v, want not exist", err)
+	}
+}
+
+func TestFileReadDir(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	if err := fs.Mkdir("dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := fs.WriteFile(fmt.Sprintf("dir/f%d", i), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := fs.Open("dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := f.(interface {
+		ReadDir(int) ([]os.DirEntry, error)
+	})
+
+	var names []string
+	for {
+		entries, err := d.ReadDir(2)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) > 2 {
+			t.Fatalf("ReadDir(2) returned %d entries", len(entries))
+		}
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	if got, want := strings.Join(names, ","), "f0,f1,f2,f3,f4"; got != want {
+		t.Errorf("paged ReadDir returned %s, want %s", got, want)
+	}
+}
+
+func TestFileOwnerTimes(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	if err := fs.WriteFile("file", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	atime := time.Date(2021, 2, 3, 4, 5, 6, 0, time.UTC)
+	mtime := time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+	if err := fs.Chtimes("file", atime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	info, err := fs.Stat("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, gid, ok := osfs.FileOwner(info)
+	switch runtime.GOOS {
+	case "windows", "plan9", "js", "wasip1", "aix":
+		if ok {
+			t.Errorf("FileOwner = %d, %d, true on %s, want ok false", uid, gid, runtime.GOOS)
+		}
+	default:
+		if !ok || uid != os.Getuid() || gid != os.Getgid() {
+			t.Errorf("FileOwner = %d, %d, %v; want %d, %d, true", uid, gid, ok, os.Getuid(), os.Getgid())
+		}
+	}
+
+	a, m, c, ok := osfs.FileTimes(info)
+	if !ok {
+		t.Skipf("FileTimes not available on %s", runtime.GOOS)
+	}
+	if !a.Equal(atime) || !m.Equal(mtime) {
+		t.Errorf("FileTimes atime, mtime = %v, %v; want %v, %v", a, m, atime, mtime)
+	}
+	if c.IsZero() {
+		t.Error("FileTimes ctime is zero")
+	}
+}
+
+func TestRealpath(t *testing.T) {
+	fs, dir := newTempFS(t)
+
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := osfs.FromNative(real)
+
+	if err := fs.Mkdir("real", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile("real/file", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		"real":             base + "/real",
+		"real/new/file":    base + "/real/new/file",
+		"missing/../real":  base + "/real",
+		"new/a/../b":       base + "/new/b",
+		"real/file":        base + "/real/file",
+		"real/./new/./dir": base + "/real/new/dir",
+	}
+	if err := fs.Symlink("real", "link"); err == nil {
+		tests["link/new/file"] = base + "/real/new/file"
+		tests["link/file"] = base + "/real/file"
+	}
+	for name, want := range tests {
+		got, err := fs.Realpath(name)
+		if err != nil {
+			t.Errorf("Realpath(%q): %v", name, err)
+		} else if got != want {
+			t.Errorf("Realpath(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	if got, err := fs.Realpath("real/file/child"); err == nil {
+		t.Errorf("Realpath through a file = %q, want an error", got)
+	}
+}
+
+func TestStatMany(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	var names []string
+	for i := 0; i < 200; i++ {
+		name := fmt.Sprintf("f%03d", i)
+		if err := fs.WriteFile(name, make([]byte, i), 0644); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	names = append(names, "missing")
+
+	results, err := fs.StatMany(names)
+	var merr osfs.MultiError
+	if !errors.As(err, &merr) || len(merr) != 1 || !os.IsNotExist(merr[0]) {
+		t.Errorf("StatMany err = %v, want one not-exist error", err)
+	}
+	if len(results) != len(names) {
+		t.Fatalf("StatMany returned %d results for %d names", len(results), len(names))
+	}
+	for i, r := range results[:200] {
+		if r.Name != names[i] || r.Err != nil || r.Info.Size() != int64(i) {
+			t.Errorf("result %d = %q, size %v, %v; want %q, size %d", i, r.Name, r.Info, r.Err, names[i], i)
+		}
+	}
+	if last := results[200]; last.Info != nil || !os.IsNotExist(last.Err) {
+		t.Errorf("result for missing = %v, %v; want a not-exist error", last.Info, last.Err)
+	}
+
+	if _, err := fs.StatMany(names[:3]); err != nil {
+		t.Errorf("StatMany of existing files: %v", err)
+	}
+}
+
+func BenchmarkStatMany(b *testing.B) {
+	fs, dir := newBenchDir(b, 1000)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		b.Fatal(err)
+	}
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = filepath.Join(dir, e.Name())
+	}
+
+	b.Run("Loop", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, name := range names {
+				if _, err := fs.Stat(name); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("StatMany", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := fs.StatMany(names); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestSyncDir(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	if err := fs.Mkdir("dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile("dir/file", []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.SyncDir("dir"); err != nil {
+		t.Errorf("SyncDir: %v", err)
+	}
+	if runtime.GOOS != "windows" {
+		if err := fs.SyncDir("missing"); !os.IsNotExist(err) {
+			t.Errorf("SyncDir of a missing directory: err = %v, want not exist", err)
+		}
+	}
+}
+
+func TestOpenDirect(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	data := osfs.AlignedBuffer(2 * osfs.DirectIOAlignment)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	f, err := fs.OpenFile("file", os.O_RDWR|os.O_CREATE|os.O_TRUNC|osfs.O_DIRECT, 0644)
+	if err != nil {
+		if errors.Is(err, osfs.ErrNotSupported) || errors.Is(err, syscall.EINVAL) {
+			t.Skipf("direct I/O not available: %v", err)
+		}
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		t.Fatal(err)
+	}
+
+	got := osfs.AlignedBuffer(len(data))
+	if _, err := f.ReadAt(got, 0); err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("data read back differs from data written")
+	}
+}
+
+func TestAlignedBuffer(t *testing.T) {
+	for _, size := range []int{0, 1, 512, osfs.DirectIOAlignment, 3*osfs.DirectIOAlignment + 7} {
+		buf := osfs.AlignedBuffer(size)
+		if len(buf) != size || cap(buf) != size {
+			t.Errorf("AlignedBuffer(%d): len %d, cap %d", size, len(buf), cap(buf))
+		}
+		if size > 0 && uintptr(unsafe.Pointer(&buf[0]))%osfs.DirectIOAlignment != 0 {
+			t.Errorf("AlignedBuffer(%d) is not aligned", size)
+		}
+	}
+}
+
+func TestCopyRangeTo(t *testing.T) {
+	fs, dir := newTempFS(t)
+
+	data := []byte("0123456789abcdefghij")
+	if err := fs.WriteFile("src", data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile("dst", []byte("xxxxxxxxxx"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	src, err := fs.Open("src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	dst, err := fs.OpenFile("dst", os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Close()
+	if _, err := src.Seek(3, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := src.(*osfs.File).CopyRangeTo(dst.(*osfs.File), 10, 4, 5)
+	if err != nil || n != 5 {
+		t.Fatalf("CopyRangeTo = %d, %v; want 5, nil", n, err)
+	}
+	// Past the end of src the copy comes up short.
+	n, err = src.(*osfs.File).CopyRangeTo(dst.(*osfs.File), 18, 12, 10)
+	if err != nil || n != 2 {
+		t.Fatalf("CopyRangeTo at EOF = %d, %v; want 2, nil", n, err)
+	}
+
+	if off, err := src.Seek(0, io.SeekCurrent); err != nil || off != 3 {
+		t.Errorf("src offset = %d, %v; want 3", off, err)
+	}
+	if off, err := dst.Seek(0, io.SeekCurrent); err != nil || off != 0 {
+		t.Errorf("dst offset = %d, %v; want 0", off, err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "dst"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "xxxxabcdex\x00\x00ij"; string(got) != want {
+		t.Errorf("dst = %q, want %q", got, want)
+	}
+}
+
+func TestFileMap(t *testing.T) {
+	fs, dir := newTempFS(t)
+
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	if err := fs.WriteFile("file", data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := fs.OpenFile("file", os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// An unaligned offset exercises the rounding to the page size.
+	const offset, length = 5003, 100
+	m, err := f.(*osfs.File).Map(offset, length, osfs.MapRead|osfs.MapWrite)
+	if errors.Is(err, osfs.ErrNotSupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := m.Bytes()
+	if !bytes.Equal(b, data[offset:offset+length]) {
+		t.Fatalf("mapped bytes = %q, want %q", b, data[offset:offset+length])
+	}
+	b[0] = 'X'
+	if err := m.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Unmap(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "file"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[offset] = 'X'
+	if !bytes.Equal(got, data) {
+		t.Error("file contents do not reflect the write through the mapping")
+	}
+}
+
+// BenchmarkReadFileInto reads 100 files of 1 KiB per iteration, reusing one
+// buffer; compare its allocations with BenchmarkReadFile.
+func BenchmarkReadFileInto(b *testing.B) {
+	var buf []byte
+	benchmarkReadFiles(b, func(fs *osfs.FileSystem, name string) error {
+		var err error
+		buf, err = fs.ReadFileInto(name, buf)
+		return err
+	})
+}
+
+func BenchmarkReadFile(b *testing.B) {
+	benchmarkReadFiles(b, func(fs *osfs.FileSystem, name string) error {
+		_, err := os.ReadFile(osfs.ToNative(name))
+		return err
+	})
+}
+
+func benchmarkReadFiles(b *testing.B, read func(*osfs.FileSystem, string) error) {
+	fs, err := osfs.NewFS()
+	if err != nil {
+		b.Fatal(err)
+	}
+	dir := b.TempDir()
+	names := make([]string, 100)
+	data := bytes.Repeat([]byte("x"), 1024)
+	for i := range names {
+		names[i] = osfs.FromNative(filepath.Join(dir, fmt.Sprintf("file%03d", i)))
+		if err := fs.WriteFile(names[i], data, 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, name := range names {
+			if err := read(fs, name); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestWalkNative(t *testing.T) {
+	fs, dir := newTempFS(t)
+
+	if err := fs.MkdirAll("root/a", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile("root/a/f", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var visited []string
+	err := fs.WalkNative("root", func(unixPath, nativePath string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if want := filepath.Join(dir, filepath.FromSlash(unixPath)); nativePath != want {
+			t.Errorf("native path of %s = %s, want %s", unixPath, nativePath, want)
+		}
+		if _, err := os.Lstat(nativePath); err != nil {
+			t.Error(err)
+		}
+		visited = append(visited, unixPath)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(visited, ","), "root,root/a,root/a/f"; got != want {
+		t.Errorf("WalkNative visited %s, want %s", got, want)
+	}
+}
+
+func TestInvalidPath(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	name := "bad\x00name"
+	_, err := fs.Open(name)
+	var perr *os.PathError
+	if !errors.Is(err, osfs.ErrInvalidPath) || !errors.As(err, &perr) || perr.Op != "open" || perr.Path != name {
+		t.Errorf("Open(%q) error = %v, want an open PathError wrapping ErrInvalidPath", name, err)
+	}
+	for op, call := range map[string]func() error{
+		"mkdir":  func() error { return fs.Mkdir(name, 0755) },
+		"stat":   func() error { _, err := fs.Stat(name); return err },
+		"remove": func() error { return fs.Remove(name) },
+		"rename": func() error { return fs.Rename("file", name) },
+		"chdir":  func() error { return fs.Chdir(name) },
+	} {
+		if err := call(); !errors.Is(err, osfs.ErrInvalidPath) {
+			t.Errorf("%s: error = %v, want ErrInvalidPath", op, err)
+		}
+	}
+
+	// The same holds for methods beyond absfs.FileSystem.
+	ctx := context.Background()
+	for method, call := range map[string]func() error{
+		"Abs":              func() error { _, err := fs.Abs(name); return err },
+		"EvalSymlinks":     func() error { _, err := fs.EvalSymlinks(name); return err },
+		"Realpath":         func() error { _, err := fs.Realpath(name); return err },
+		"Symlink":          func() error { return fs.Symlink(name, "link") },
+		"SameFile":         func() error { _, err := fs.SameFile("file", name); return err },
+		"GetXattr":         func() error { _, err := fs.GetXattr(name, "user.x"); return err },
+		"Chflags":          func() error { return fs.Chflags(name, 0) },
+		"CopyFile":         func() error { _, err := fs.CopyFile(name, "file"); return err },
+		"CopyTree":         func() error { return fs.CopyTree("copy", name, osfs.CopyOptions{}) },
+		"CopyMetadata":     func() error { return fs.CopyMetadata(name, "file", osfs.MetaMode) },
+		"Move":             func() error { return fs.Move(name, "moved") },
+		"Glob":             func() error { _, err := fs.Glob(name + "*"); return err },
+		"Statfs":           func() error { _, err := fs.Statfs(name); return err },
+		"IsMountPoint":     func() error { _, err := fs.IsMountPoint(name); return err },
+		"Lchtimes":         func() error { return fs.Lchtimes(name, time.Now(), time.Now()) },
+		"LinkCount":        func() error { _, err := fs.LinkCount(name); return err },
+		"MaxNameLength":    func() error { _, err := fs.MaxNameLength(name); return err },
+		"OpenLocked":       func() error { _, err := fs.OpenLocked(name, os.O_RDONLY, 0, false); return err },
+		"CreateTemp":       func() error { _, err := fs.CreateTemp(name, ""); return err },
+		"WriteFileAtomic":  func() error { return fs.WriteFileAtomic(name, nil, 0644) },
+		"ReadFileContext":  func() error { _, err := fs.ReadFileContext(ctx, name); return err },
+		"RemoveAllContext": func() error { return fs.RemoveAllContext(ctx, name, nil) },
+		"IsEmpty":          func() error { _, err := fs.IsEmpty(name); return err },
+		"RealName":         func() error { _, err := fs.RealName(name); return err },
+		"RangeDir":         func() error { return fs.RangeDir(name, nil) },
+		"WalkDir": func() error {
+			return fs.WalkDir(name, func(_ string, _ iofs.DirEntry, err error) error { return err })
+		},
+	} {
+		if err := call(); !errors.Is(err, osfs.ErrInvalidPath) {
+			t.Errorf("%s: error = %v, want ErrInvalidPath", method, err)
+		}
+	}
+	if fs.Exists(name) || fs.IsDir(name) || fs.IsRegular(name) {
+		t.Error("an invalid name was reported to exist")
+	}
+
+	// Valid names are unaffected.
+	if err := fs.WriteFile("file", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.Stat("file"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRemoveTreeSecure(t *testing.T) {
+	fs, dir := newTempFS(t)
+
+	// Resolve links in the temporary directory itself, such as /tmp on
+	// macOS, which RemoveTreeSecure would refuse.
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Chdir(real); err != nil {
+		t.Fatal(err)
+	}
+
+	// A tree holding a link to a directory outside it, whose contents must
+	// survive.
+	for _, d := range []string{"victim", "tree/sub"} {
+		if err := os.MkdirAll(filepath.Join(real, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{"victim/keep", "tree/file", "tree/sub/file"} {
+		if err := os.WriteFile(filepath.Join(real, f), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(real, "victim"), filepath.Join(real, "tree", "link")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	err = fs.RemoveTreeSecure("tree")
+	if errors.Is(err, osfs.ErrNotSupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Lstat(filepath.Join(real, "tree")); !os.IsNotExist(err) {
+		t.Errorf("tree still exists: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(real, "victim", "keep")); err != nil {
+		t.Errorf("file behind link was removed: %v", err)
+	}
+
+	// A path through a link, as left by an attacker swapping a directory
+	// for a link to the victim, is refused at any component.
+	if err := os.Symlink(filepath.Join(real, "victim"), filepath.Join(real, "swapped")); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"swapped", "swapped/keep"} {
+		if err := fs.RemoveTreeSecure(name); !errors.Is(err, osfs.ErrSymlinkInPath) {
+			t.Errorf("RemoveTreeSecure(%q) = %v, want ErrSymlinkInPath", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(real, "victim", "keep")); err != nil {
+		t.Errorf("file behind link was removed: %v", err)
+	}
+
+	if err := fs.RemoveTreeSecure("missing"); err != nil {
+		t.Errorf("RemoveTreeSecure(missing) = %v, want nil", err)
+	}
+}
+
+func TestCreateMode(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	f, err := fs.CreateMode("secret", 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("x")); err != nil {
+		t.Error(err)
+	}
+	f.Close()
+
+	info, err := fs.Stat("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Windows only records whether a file is read-only.
+	if runtime.GOOS != "windows" {
+		// The umask can only clear bits, so nothing beyond 0600 may be set;
+		// no sensible umask clears the owner's bits.
+		if mode := info.Mode().Perm(); mode != 0600 {
+			t.Errorf("mode = %v, want %v", mode, os.FileMode(0600))
+		}
+	}
+
+	// An existing file is truncated.
+	f, err = fs.CreateMode("secret", 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if info, err := fs.Stat("secret"); err != nil || info.Size() != 0 {
+		t.Errorf("Stat after second CreateMode = %v, %v; want size 0", info, err)
+	}
+}
+
+func TestAppendFile(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	if err := fs.AppendToFile("log", []byte("one\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := fs.AppendFile("log", 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Appends go to the end whatever the file offset.
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("two\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := fs.ReadFileInto("log", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "one\ntwo\n" {
+		t.Errorf("read %q, want %q", data, "one\ntwo\n")
+	}
+}
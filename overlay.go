@@ -0,0 +1,294 @@
+package osfs
+
+import (
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/absfs/absfs"
+)
+
+// Overlay returns a FileSystem that layers the given FileSystems on top of
+// each other, the first one on top, as MergeFS does for directories. Stat,
+// Open and opening a file read-only use the first layer that has the name,
+// so a file in an upper layer shadows any file or directory of the same
+// name below it. Listing a directory merges the directory across every
+// layer, from the first that has the name down, in which the name is a
+// directory too; a layer in which it is a file is skipped. Each name is
+// listed once, described by the uppermost layer that has it.
+//
+// Every operation that modifies the filesystem, including opening a file
+// for writing, goes to the top layer alone. There are no whiteouts: removing
+// a name from the top layer uncovers the same name in the layers below, and
+// writing to a file that exists only in a lower layer fails or creates a new
+// file in the top layer, as the top layer decides.
+//
+// Names are passed to every layer as they are, so relative names resolve
+// against each layer's own working directory. Layers are typically Chroot
+// views or FileSystems whose working directory is the root of the layer.
+// Chdir changes the working directory of every layer in which dir is a
+// directory, and Getwd reports that of the top layer. The returned
+// FileSystem also implements absfs.SymLinker; layers that do not are
+// skipped by Lstat and Readlink. Overlay panics if no layers are given.
+func Overlay(layers ...absfs.FileSystem) absfs.FileSystem {
+	if len(layers) == 0 {
+		panic("osfs: Overlay needs at least one layer")
+	}
+	return overlayFS(layers)
+}
+
+// overlayFS is a stack of FileSystems, top first.
+type overlayFS []absfs.FileSystem
+
+// lookup returns the index and info of the first layer that contains name.
+func (o overlayFS) lookup(op, name string) (int, os.FileInfo, error) {
+	for i, layer := range o {
+		info, err := layer.Stat(name)
+		if err == nil {
+			return i, info, nil
+		}
+		if !os.IsNotExist(err) {
+			return 0, nil, err
+		}
+	}
+	return 0, nil, &os.PathError{Op: op, Path: name, Err: os.ErrNotExist}
+}
+
+func (o overlayFS) OpenFile(name string, flag int, perm os.FileMode) (absfs.File, error) {
+	if flag&writeFlags != 0 {
+		return o.top().OpenFile(name, flag, perm)
+	}
+	i, info, err := o.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := o[i].OpenFile(name, flag, perm)
+	if err != nil || !info.IsDir() {
+		return f, err
+	}
+	return &overlayDir{File: f, o: o[i:], name: name}, nil
+}
+
+func (o overlayFS) Open(name string) (absfs.File, error) {
+	return o.OpenFile(name, os.O_RDONLY, 0)
+}
+
+func (o overlayFS) Stat(name string) (os.FileInfo, error) {
+	_, info, err := o.lookup("stat", name)
+	return info, err
+}
+
+// top returns the layer that receives modifications.
+func (o overlayFS) top() absfs.FileSystem {
+	return o[0]
+}
+
+func (o overlayFS) Create(name string) (absfs.File, error) {
+	return o.top().Create(name)
+}
+
+func (o overlayFS) Mkdir(name string, perm os.FileMode) error {
+	return o.top().Mkdir(name, perm)
+}
+
+func (o overlayFS) MkdirAll(name string, perm os.FileMode) error {
+	return o.top().MkdirAll(name, perm)
+}
+
+func (o overlayFS) Remove(name string) error {
+	return o.top().Remove(name)
+}
+
+func (o overlayFS) RemoveAll(name string) error {
+	return o.top().RemoveAll(name)
+}
+
+func (o overlayFS) Rename(oldpath, newpath string) error {
+	return o.top().Rename(oldpath, newpath)
+}
+
+func (o overlayFS) Chmod(name string, mode os.FileMode) error {
+	return o.top().Chmod(name, mode)
+}
+
+func (o overlayFS) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	return o.top().Chtimes(name, atime, mtime)
+}
+
+func (o overlayFS) Chown(name string, uid, gid int) error {
+	return o.top().Chown(name, uid, gid)
+}
+
+func (o overlayFS) Truncate(name string, size int64) error {
+	return o.top().Truncate(name, size)
+}
+
+func (o overlayFS) Chdir(dir string) error {
+	var first error
+	changed := false
+	for _, layer := range o {
+		info, err := layer.Stat(dir)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		if err := layer.Chdir(dir); err != nil {
+			if first == nil {
+				first = err
+			}
+			continue
+		}
+		changed = true
+	}
+	if changed {
+		return nil
+	}
+	if first == nil {
+		first = &os.PathError{Op: "chdir", Path: dir, Err: os.ErrNotExist}
+	}
+	return first
+}
+
+func (o overlayFS) Getwd() (string, error) { return o.top().Getwd() }
+func (o overlayFS) Separator() uint8       { return o.top().Separator() }
+func (o overlayFS) ListSeparator() uint8   { return o.top().ListSeparator() }
+func (o overlayFS) TempDir() string        { return o.top().TempDir() }
+
+func (o overlayFS) Lstat(name string) (os.FileInfo, error) {
+	for _, layer := range o {
+		sl, ok := layer.(absfs.SymLinker)
+		if !ok {
+			continue
+		}
+		info, err := sl.Lstat(name)
+		if !os.IsNotExist(err) {
+			return info, err
+		}
+	}
+	return nil, &os.PathError{Op: "lstat", Path: name, Err: os.ErrNotExist}
+}
+
+func (o overlayFS) Readlink(name string) (string, error) {
+	for _, layer := range o {
+		sl, ok := layer.(absfs.SymLinker)
+		if !ok {
+			continue
+		}
+		target, err := sl.Readlink(name)
+		if !os.IsNotExist(err) {
+			return target, err
+		}
+	}
+	return "", &os.PathError{Op: "readlink", Path: name, Err: os.ErrNotExist}
+}
+
+func (o overlayFS) Lchown(name string, uid, gid int) error {
+	sl, ok := o.top().(absfs.SymLinker)
+	if !ok {
+		return &os.PathError{Op: "lchown", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	return sl.Lchown(name, uid, gid)
+}
+
+func (o overlayFS) Symlink(oldname, newname string) error {
+	sl, ok := o.top().(absfs.SymLinker)
+	if !ok {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: absfs.ErrNotImplemented}
+	}
+	return sl.Symlink(oldname, newname)
+}
+
+// overlayDir is a directory opened through an overlayFS. It is the
+// directory of the uppermost layer that has the name, listing the merged
+// contents of the layers o. The listing is read on first use.
+type overlayDir struct {
+	absfs.File
+	o      overlayFS
+	name   string
+	infos  []os.FileInfo
+	read   bool
+	offset int
+}
+
+func (d *overlayDir) merge() error {
+	if d.read {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for i, layer := range d.o {
+		var list []os.FileInfo
+		var err error
+		if i == 0 {
+			list, err = d.File.Readdir(-1)
+		} else {
+			list, err = readLayerDir(layer, d.name)
+		}
+		if err != nil {
+			return err
+		}
+		for _, info := range list {
+			if !seen[info.Name()] {
+				seen[info.Name()] = true
+				d.infos = append(d.infos, info)
+			}
+		}
+	}
+	sort.Slice(d.infos, func(i, j int) bool { return d.infos[i].Name() < d.infos[j].Name() })
+	d.read = true
+	return nil
+}
+
+// readLayerDir lists name in a lower layer, or returns nothing if name is
+// not a directory there.
+func readLayerDir(layer absfs.FileSystem, name string) ([]os.FileInfo, error) {
+	info, err := layer.Stat(name)
+	if os.IsNotExist(err) || err == nil && !info.IsDir() {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	f, err := layer.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Readdir(-1)
+}
+
+func (d *overlayDir) Readdir(n int) ([]os.FileInfo, error) {
+	if err := d.merge(); err != nil {
+		return nil, err
+	}
+	rest := d.infos[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.infos)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.offset += n
+	return rest[:n], nil
+}
+
+func (d *overlayDir) Readdirnames(n int) ([]string, error) {
+	infos, err := d.Readdir(n)
+	names := make([]string, len(infos))
+	for i, info := range infos {
+		names[i] = info.Name()
+	}
+	return names, err
+}
+
+// Seek rewinds the listing when seeking to the start, as os.File does for
+// directories.
+func (d *overlayDir) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		d.offset = 0
+	}
+	return d.File.Seek(offset, whence)
+}
@@ -0,0 +1,127 @@
+package osfs_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/osfs"
+)
+
+func TestOverlay(t *testing.T) {
+	base, dir := newTempFS(t)
+
+	files := map[string]string{
+		"top/a.txt":        "top",
+		"top/dir/b.txt":    "top",
+		"top/shadow":       "file",
+		"bottom/a.txt":     "bottom",
+		"bottom/c.txt":     "bottom",
+		"bottom/dir/b.txt": "bottom",
+		"bottom/dir/d.txt": "bottom",
+		"bottom/shadow/e":  "hidden",
+	}
+	for name, data := range files {
+		if err := base.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := base.WriteFile(name, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var layers []absfs.FileSystem
+	for _, name := range []string{"top", "bottom"} {
+		layer, err := osfs.Chroot(base, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		layers = append(layers, layer)
+	}
+	fs := osfs.Overlay(layers...)
+
+	read := func(name string) string {
+		t.Helper()
+		f, err := fs.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		data, err := io.ReadAll(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	for name, want := range map[string]string{
+		"/a.txt":     "top",
+		"/c.txt":     "bottom",
+		"/dir/b.txt": "top",
+		"/dir/d.txt": "bottom",
+		"/shadow":    "file",
+	} {
+		if got := read(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	if _, err := fs.Stat("/shadow/e"); err == nil {
+		t.Error("file below a shadowed directory is visible")
+	}
+
+	list := func(name string) string {
+		t.Helper()
+		f, err := fs.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		names, err := f.Readdirnames(-1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Join(names, ",")
+	}
+	if got, want := list("/"), "a.txt,c.txt,dir,shadow"; got != want {
+		t.Errorf("root lists %s, want %s", got, want)
+	}
+	if got, want := list("/dir"), "b.txt,d.txt"; got != want {
+		t.Errorf("/dir lists %s, want %s", got, want)
+	}
+
+	f, err := fs.Open("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	infos, err := f.Readdir(-1)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, info := range infos {
+		if info.Name() == "shadow" && info.IsDir() {
+			t.Error("shadow is listed as a directory, not as the top layer's file")
+		}
+	}
+
+	// Writes go to the top layer.
+	w, err := fs.OpenFile("/c.txt", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteString("new")
+	w.Close()
+	if data, err := os.ReadFile(filepath.Join(dir, "top", "c.txt")); err != nil || string(data) != "new" {
+		t.Errorf("top/c.txt = %q, %v; want the written data", data, err)
+	}
+	if got := read("/c.txt"); got != "new" {
+		t.Errorf("/c.txt = %q after writing, want %q", got, "new")
+	}
+	if err := fs.Remove("/c.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if got := read("/c.txt"); got != "bottom" {
+		t.Errorf("/c.txt = %q after removing it from the top layer, want %q", got, "bottom")
+	}
+}
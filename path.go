@@ -0,0 +1,231 @@
+package osfs
+
+import (
+	"errors"
+	"path"
+	"runtime"
+	"strings"
+)
+
+// absfs paths are Unix-style on every platform. Windows volumes are written
+// as a leading single letter drive component (`/c/Users`) or as a UNC prefix
+// (`//server/share/dir`). The functions in this file operate purely on such
+// strings and never touch the filesystem.
+
+// volumeNameLen returns the length of the leading volume of an absfs path:
+// `/c` for drive paths, `//server/share` for UNC paths and 0 otherwise.
+func volumeNameLen(p string) int {
+	if len(p) >= 2 && p[0] == '/' && isDriveLetter(p[1]) && (len(p) == 2 || p[2] == '/') {
+		return 2
+	}
+	if len(p) < 3 || p[0] != '/' || p[1] != '/' || p[2] == '/' {
+		return 0
+	}
+
+	// UNC: `//server/share`
+	n := 2
+	for n < len(p) && p[n] != '/' {
+		n++
+	}
+	if n+1 >= len(p) || p[n+1] == '/' {
+		return n
+	}
+	n++
+	for n < len(p) && p[n] != '/' {
+		n++
+	}
+	return n
+}
+
+func isDriveLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// Clean returns the shortest absfs path equivalent to p. It behaves like
+// path.Clean, except that a leading drive (`/c`) or UNC prefix
+// (`//server/share`) is kept intact and `..` elements never climb above it.
+// The root of a volume is returned with a trailing slash, e.g. `/c/`.
+func Clean(p string) string {
+	n := volumeNameLen(p)
+	if n == 0 {
+		return path.Clean(p)
+	}
+	return p[:n] + path.Clean("/"+p[n:])
+}
+
+// Rel returns a relative path that is lexically equivalent to targpath when
+// joined to basepath. Both paths must be on the same volume: an error is
+// returned when they have different drives, when only one of them is a UNC
+// path, or when only one of them is rooted. Drive letters and UNC prefixes
+// are compared case-insensitively.
+func Rel(basepath, targpath string) (string, error) {
+	base := Clean(basepath)
+	targ := Clean(targpath)
+	bvol := base[:volumeNameLen(base)]
+	tvol := targ[:volumeNameLen(targ)]
+	if !strings.EqualFold(bvol, tvol) {
+		return "", errors.New("Rel: can't make " + targpath + " relative to " + basepath)
+	}
+	base = base[len(bvol):]
+	targ = targ[len(tvol):]
+	if base == targ {
+		return ".", nil
+	}
+	if base == "." {
+		base = ""
+	}
+
+	baseRooted := len(base) > 0 && base[0] == '/'
+	targRooted := len(targ) > 0 && targ[0] == '/'
+	if baseRooted != targRooted {
+		return "", errors.New("Rel: can't make " + targpath + " relative to " + basepath)
+	}
+
+	// Position base[b0:bi] and targ[t0:ti] at the first differing elements.
+	bl := len(base)
+	tl := len(targ)
+	var b0, bi, t0, ti int
+	for {
+		for bi < bl && base[bi] != '/' {
+			bi++
+		}
+		for ti < tl && targ[ti] != '/' {
+			ti++
+		}
+		if targ[t0:ti] != base[b0:bi] {
+			break
+		}
+		if bi < bl {
+			bi++
+		}
+		if ti < tl {
+			ti++
+		}
+		b0 = bi
+		t0 = ti
+	}
+	if base[b0:bi] == ".." {
+		return "", errors.New("Rel: can't make " + targpath + " relative to " + basepath)
+	}
+	if b0 == bl {
+		return targ[t0:], nil
+	}
+
+	// Base elements left over must be climbed out of with "..".
+	seps := strings.Count(base[b0:bl], "/")
+	rel := ".." + strings.Repeat("/..", seps)
+	if t0 != tl {
+		rel += "/" + targ[t0:]
+	}
+	return rel, nil
+}
+
+// Match reports whether name matches the absfs shell pattern. The pattern
+// syntax is that of path.Match and `/` is the only separator on every
+// platform. A leading drive or UNC volume is matched literally and
+// case-insensitively, so `/c/*` matches `/C/foo` but not `/d/foo`, and a
+// pattern without a volume never matches a name that has one.
+func Match(pattern, name string) (matched bool, err error) {
+	pv := volumeNameLen(pattern)
+	nv := volumeNameLen(name)
+	matched, err = path.Match(pattern[pv:], name[nv:])
+	if err != nil || !matched {
+		return false, err
+	}
+	return strings.EqualFold(pattern[:pv], name[:nv]), nil
+}
+
+// Split splits p immediately following its final slash, separating it into
+// a directory and a file name component, like path.Split. A volume is never
+// split: `//server/share` is all directory. The returned values have the
+// property that p = dir+file.
+func Split(p string) (dir, file string) {
+	n := volumeNameLen(p)
+	i := strings.LastIndex(p[n:], "/")
+	return p[:n+i+1], p[n+i+1:]
+}
+
+// Dir returns all but the last element of p, that is the directory of p,
+// cleaned with Clean. Unlike path.Dir it keeps volumes intact, so the
+// directory of `/c/foo` is `/c/` and that of `//server/share/x` is
+// `//server/share/`. If p is empty, Dir returns ".".
+func Dir(p string) string {
+	dir, _ := Split(p)
+	return Clean(dir)
+}
+
+// Base returns the last element of p, after removing trailing slashes. The
+// share name is the last element of a bare UNC path, so Base of
+// `//server/share` is `share`; the root of a drive, like `/c/`, has none
+// and Base returns "/" for it as for "/". If p is empty, Base returns ".".
+func Base(p string) string {
+	if p == "" {
+		return "."
+	}
+	n := volumeNameLen(p)
+	if n > 0 && strings.Trim(p[n:], "/") == "" {
+		if p[1] == '/' {
+			return path.Base(p[:n])
+		}
+		return "/"
+	}
+	return path.Base(p)
+}
+
+// Components returns the elements of the cleaned p. The root of an
+// absolute path is the first element, written as Clean writes it: "/",
+// `/c/` or `//server/share/`. Components returns nil for "." and the
+// empty path, so len(Components(p)) is the depth of p.
+func Components(p string) []string {
+	p = Clean(p)
+	if p == "." {
+		return nil
+	}
+	var elems []string
+	if n := volumeNameLen(p); n > 0 {
+		elems = append(elems, p[:n]+"/")
+		p = p[n:]
+	} else if p[0] == '/' {
+		elems = append(elems, "/")
+	}
+	for _, e := range strings.Split(p, "/") {
+		if e != "" {
+			elems = append(elems, e)
+		}
+	}
+	return elems
+}
+
+// VolumeName returns the leading volume of the absfs path p: `/c` for a
+// drive path, `//server/share` for a UNC path, and "" when p has no volume.
+// Like filepath.VolumeName it does not clean p or check that it exists.
+func VolumeName(p string) string {
+	return p[:volumeNameLen(p)]
+}
+
+// IsAbs reports whether the absfs path p is absolute, that is whether it
+// starts with a slash: `/foo`, `/c/foo` and `//server/share/foo` are
+// absolute, while `foo/bar`, `c/bar` and the drive-relative `c:foo` are not.
+// A path like `/foo` has no volume; on Windows FileSystem methods resolve it
+// against the volume of the working directory, so it is rooted but does not
+// name the same file from every working directory. Use VolumeName to tell
+// such paths apart.
+func IsAbs(p string) bool {
+	return strings.HasPrefix(p, "/")
+}
+
+// validPath reports whether the absfs path p can name a file at all. It
+// rejects NUL bytes, which no system allows in names, and on Windows a UNC
+// prefix without a share, which the system would look up on the network
+// before failing.
+func validPath(p string) bool {
+	if strings.IndexByte(p, 0) >= 0 {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		if n := volumeNameLen(p); n > 2 && strings.IndexByte(p[2:n], '/') < 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,287 @@
+package osfs_test
+
+import (
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/absfs/osfs"
+)
+
+func TestClean(t *testing.T) {
+	tests := []struct {
+		in, out string
+	}{
+		{"", "."},
+		{".", "."},
+		{"a/b/../c", "a/c"},
+		{"/", "/"},
+		{"//", "/"},
+		{"/foo//bar/", "/foo/bar"},
+		{"/../foo", "/foo"},
+
+		// drives
+		{"/c", "/c/"},
+		{"/c/", "/c/"},
+		{"/c/..", "/c/"},
+		{"/c/../..", "/c/"},
+		{"/c/foo/../bar", "/c/bar"},
+		{"/c//foo/./bar/", "/c/foo/bar"},
+		{"/c/../../d/foo", "/c/d/foo"},
+		{"/cat/..", "/"},
+
+		// UNC
+		{"//server/share", "//server/share/"},
+		{"//server/share/./x", "//server/share/x"},
+		{"//server/share/x/../..", "//server/share/"},
+		{"//server/share//x/", "//server/share/x"},
+		{"//server", "//server/"},
+		{"///foo", "/foo"},
+	}
+
+	for _, test := range tests {
+		if got := osfs.Clean(test.in); got != test.out {
+			t.Errorf("Clean(%q) = %q, want %q", test.in, got, test.out)
+		}
+	}
+}
+
+func TestRel(t *testing.T) {
+	tests := []struct {
+		base, targ, rel string
+	}{
+		{"/c/a/b", "/c/a/b/c/d", "c/d"},
+		{"/c/a", "/c/b", "../b"},
+		{"/c/a/b", "/c/a/b", "."},
+		{"/c/a/b/c", "/c/a", "../.."},
+		{"/c/", "/c/x/y", "x/y"},
+		{"/C/a", "/c/a/b", "b"},
+		{"//server/share/a", "//server/share/b/c", "../b/c"},
+		{"//server/share", "//SERVER/share/x", "x"},
+		{"/foo/bar", "/foo/baz/qux", "../baz/qux"},
+		{"/", "/foo/bar", "foo/bar"},
+		{"a/b", "a/c", "../c"},
+		{".", "a/b", "a/b"},
+	}
+	for _, test := range tests {
+		got, err := osfs.Rel(test.base, test.targ)
+		if err != nil {
+			t.Errorf("Rel(%q, %q): %v", test.base, test.targ, err)
+			continue
+		}
+		if got != test.rel {
+			t.Errorf("Rel(%q, %q) = %q, want %q", test.base, test.targ, got, test.rel)
+		}
+	}
+
+	errTests := []struct {
+		base, targ string
+	}{
+		{"/c/x", "/d/y"},
+		{"/c/x", "//server/share/x"},
+		{"//server/share/x", "//server/other/x"},
+		{"/c/x", "/x"},
+		{"/foo", "bar"},
+		{"..", "a"},
+	}
+	for _, test := range errTests {
+		if got, err := osfs.Rel(test.base, test.targ); err == nil {
+			t.Errorf("Rel(%q, %q) = %q, want an error", test.base, test.targ, got)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		match         bool
+	}{
+		{"/c/*", "/c/foo", true},
+		{"/c/*", "/C/foo", true},
+		{"/c/*", "/d/foo", false},
+		{"/c/*", "/c/foo/bar", false},
+		{"/c/*/bar", "/c/foo/bar", true},
+		{"/*/foo", "/c/foo", false},
+		{"/*/foo", "/usr/foo", true},
+		{"//server/share/*.txt", "//SERVER/share/a.txt", true},
+		{"//server/share/*.txt", "//server/other/a.txt", false},
+		{"/c/*", "//server/share/foo", false},
+		{"*.go", "path.go", true},
+		{"a/[bc]/d", "a/c/d", true},
+		{"a/?/d", "a/xy/d", false},
+	}
+	for _, test := range tests {
+		match, err := osfs.Match(test.pattern, test.name)
+		if err != nil {
+			t.Errorf("Match(%q, %q): %v", test.pattern, test.name, err)
+			continue
+		}
+		if match != test.match {
+			t.Errorf("Match(%q, %q) = %v, want %v", test.pattern, test.name, match, test.match)
+		}
+	}
+
+	if _, err := osfs.Match("/c/[", "/d/x"); err != path.ErrBadPattern {
+		t.Errorf("Match with a bad pattern: err = %v, want %v", err, path.ErrBadPattern)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		in, dir, file string
+	}{
+		{"", "", ""},
+		{"a", "", "a"},
+		{"a/b", "a/", "b"},
+		{"/foo", "/", "foo"},
+		{"/foo/", "/foo/", ""},
+		{"/c", "/c", ""},
+		{"/c/", "/c/", ""},
+		{"/c/foo", "/c/", "foo"},
+		{"/c/foo/bar", "/c/foo/", "bar"},
+		{"/cat", "/", "cat"},
+		{"//server/share", "//server/share", ""},
+		{"//server/share/", "//server/share/", ""},
+		{"//server/share/x", "//server/share/", "x"},
+		{"//server", "//server", ""},
+	}
+	for _, test := range tests {
+		dir, file := osfs.Split(test.in)
+		if dir != test.dir || file != test.file {
+			t.Errorf("Split(%q) = %q, %q, want %q, %q", test.in, dir, file, test.dir, test.file)
+		}
+	}
+}
+
+func TestDir(t *testing.T) {
+	tests := []struct {
+		in, out string
+	}{
+		{"", "."},
+		{"a", "."},
+		{"a/b/", "a/b"},
+		{"a/b/c", "a/b"},
+		{"/", "/"},
+		{"/foo", "/"},
+		{"/c", "/c/"},
+		{"/c/", "/c/"},
+		{"/c/foo", "/c/"},
+		{"/c/foo/bar", "/c/foo"},
+		{"/cat/foo", "/cat"},
+		{"//server/share", "//server/share/"},
+		{"//server/share/x", "//server/share/"},
+		{"//server/share/x/y", "//server/share/x"},
+	}
+	for _, test := range tests {
+		if got := osfs.Dir(test.in); got != test.out {
+			t.Errorf("Dir(%q) = %q, want %q", test.in, got, test.out)
+		}
+	}
+}
+
+func TestBase(t *testing.T) {
+	tests := []struct {
+		in, out string
+	}{
+		{"", "."},
+		{".", "."},
+		{"a", "a"},
+		{"a/b/", "b"},
+		{"/", "/"},
+		{"//", "/"},
+		{"/foo/bar", "bar"},
+		{"/c", "/"},
+		{"/c/", "/"},
+		{"/C//", "/"},
+		{"/c/foo", "foo"},
+		{"/c/foo/", "foo"},
+		{"/cat", "cat"},
+		{"//server/share", "share"},
+		{"//server/share/", "share"},
+		{"//server/share/x", "x"},
+		{"//server", "server"},
+	}
+	for _, test := range tests {
+		if got := osfs.Base(test.in); got != test.out {
+			t.Errorf("Base(%q) = %q, want %q", test.in, got, test.out)
+		}
+	}
+}
+
+func TestComponents(t *testing.T) {
+	tests := []struct {
+		in  string
+		out []string
+	}{
+		{"", nil},
+		{".", nil},
+		{"a", []string{"a"}},
+		{"a/b/../c/", []string{"a", "c"}},
+		{"../a", []string{"..", "a"}},
+		{"/", []string{"/"}},
+		{"/foo/bar", []string{"/", "foo", "bar"}},
+		{"/c", []string{"/c/"}},
+		{"/c/foo/bar", []string{"/c/", "foo", "bar"}},
+		{"/cat/foo", []string{"/", "cat", "foo"}},
+		{"//server/share", []string{"//server/share/"}},
+		{"//server/share/x/y", []string{"//server/share/", "x", "y"}},
+	}
+	for _, test := range tests {
+		got := osfs.Components(test.in)
+		if len(got) != len(test.out) || strings.Join(got, "|") != strings.Join(test.out, "|") {
+			t.Errorf("Components(%q) = %q, want %q", test.in, got, test.out)
+		}
+	}
+}
+
+func TestVolumeName(t *testing.T) {
+	tests := []struct {
+		in, out string
+	}{
+		{"", ""},
+		{".", ""},
+		{"c", ""},
+		{"c/foo", ""},
+		{"/", ""},
+		{"/foo/bar", ""},
+		{"/cat", ""},
+		{"/c", "/c"},
+		{"/c/", "/c"},
+		{"/C/foo/bar", "/C"},
+		{"//server/share", "//server/share"},
+		{"//server/share/", "//server/share"},
+		{"//server/share/x/y", "//server/share"},
+		{"//server", "//server"},
+		{"///foo", ""},
+	}
+	for _, test := range tests {
+		if got := osfs.VolumeName(test.in); got != test.out {
+			t.Errorf("VolumeName(%q) = %q, want %q", test.in, got, test.out)
+		}
+	}
+}
+
+func TestIsAbs(t *testing.T) {
+	tests := []struct {
+		in  string
+		abs bool
+	}{
+		{"", false},
+		{".", false},
+		{"foo/bar", false},
+		{"c/bar", false},
+		{"c:foo", false},
+		{"../foo", false},
+		{"/", true},
+		{"/foo", true},
+		{"/c", true},
+		{"/c/foo", true},
+		{"//server/share", true},
+		{"//server/share/foo", true},
+	}
+	for _, test := range tests {
+		if got := osfs.IsAbs(test.in); got != test.abs {
+			t.Errorf("IsAbs(%q) = %v, want %v", test.in, got, test.abs)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+//go:build !windows
+// +build !windows
+
+package osfs
+
+import "path/filepath"
+
+// ToNative converts an absfs path to the native path format. absfs paths are
+// already native on Unix-like systems.
+func ToNative(p string) string {
+	return filepath.FromSlash(p)
+}
+
+// ToNativeExtended is ToNative. Path lengths need no special form on
+// Unix-like systems.
+func ToNativeExtended(p string) string {
+	return ToNative(p)
+}
+
+// FromNative converts a native path to an absfs path.
+func FromNative(p string) string {
+	return filepath.ToSlash(p)
+}
@@ -0,0 +1,77 @@
+package osfs
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// ToNative converts an absfs path to a native Windows path. Drive paths such
+// as `/c/foo` become `C:\foo` and UNC paths such as `//server/share/foo`
+// become `\\server\share\foo`. Drive-relative paths such as `c:foo` become
+// `C:foo`. Paths that already carry a native volume, and paths without one,
+// only have their separators converted.
+func ToNative(p string) string {
+	if vol := filepath.VolumeName(p); len(vol) == 2 && vol[1] == ':' {
+		p = strings.ToUpper(p[:1]) + p[1:]
+	} else if vol == "" && volumeNameLen(p) == 2 {
+		rest := p[2:]
+		if rest == "" {
+			rest = "/"
+		}
+		p = strings.ToUpper(p[1:2]) + ":" + rest
+	}
+	return filepath.FromSlash(p)
+}
+
+// ToNativeExtended is ToNative for paths handed to Windows APIs limited to
+// MAX_PATH (260) characters. If the native form of an absolute path comes
+// close to the limit it is returned with the extended-length prefix,
+// `\\?\C:\...` or `\\?\UNC\server\share\...`, which lifts it. The os
+// package already does this for its own calls.
+func ToNativeExtended(p string) string {
+	p = ToNative(p)
+	if len(p) < longPathLen || !filepath.IsAbs(p) || strings.HasPrefix(p, `\\?\`) {
+		return p
+	}
+	// Extended-length paths are not normalized by Windows, so clean first.
+	p = filepath.Clean(p)
+	if strings.HasPrefix(p, `\\`) {
+		return `\\?\UNC\` + p[2:]
+	}
+	return `\\?\` + p
+}
+
+// longPathLen is the length from which ToNativeExtended adds the prefix. It
+// is below MAX_PATH because directory creation leaves room for an 8.3 file
+// name; the os package uses the same bound.
+const longPathLen = 248
+
+// FromNative converts a native Windows path to an absfs path. `C:\foo`
+// becomes `/c/foo` and `\\server\share\foo` becomes `//server/share/foo`.
+// The extended-length forms `\\?\C:\foo` and `\\?\UNC\server\share\foo`
+// convert to the same absfs paths as their plain counterparts.
+//
+// A drive-relative path such as `C:foo\bar`, which names foo\bar relative to
+// the current directory of drive C, has no absfs equivalent. It is kept
+// relative as `c:foo/bar`, which ToNative turns back into `C:foo\bar` and
+// which FileSystem methods resolve against the working directory when it is
+// on drive C, or against the process's current directory of that drive
+// otherwise.
+func FromNative(p string) string {
+	if strings.HasPrefix(p, `\\?\UNC\`) {
+		p = `\\` + p[len(`\\?\UNC\`):]
+	} else if strings.HasPrefix(p, `\\?\`) {
+		p = p[len(`\\?\`):]
+	}
+
+	vol := filepath.VolumeName(p)
+	if len(vol) != 2 || vol[1] != ':' {
+		return filepath.ToSlash(p)
+	}
+
+	rest := filepath.ToSlash(p[2:])
+	if !strings.HasPrefix(rest, "/") {
+		return strings.ToLower(vol[:1]) + ":" + rest
+	}
+	return "/" + strings.ToLower(vol[:1]) + rest
+}
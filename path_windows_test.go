@@ -0,0 +1,168 @@
+package osfs_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/absfs/osfs"
+)
+
+func TestNativeConversion(t *testing.T) {
+	tests := []struct {
+		absfs, native string
+	}{
+		{"/c/", `C:\`},
+		{"/c/foo/bar", `C:\foo\bar`},
+		{"//server/share/foo", `\\server\share\foo`},
+		{"foo/bar", `foo\bar`},
+	}
+	for _, test := range tests {
+		if got := osfs.ToNative(test.absfs); got != test.native {
+			t.Errorf("ToNative(%q) = %q, want %q", test.absfs, got, test.native)
+		}
+		if got := osfs.FromNative(test.native); got != test.absfs {
+			t.Errorf("FromNative(%q) = %q, want %q", test.native, got, test.absfs)
+		}
+	}
+}
+
+func TestAbsDriveInheritance(t *testing.T) {
+	fs, err := osfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Chdir(`C:\`); err != nil {
+		t.Skip(err)
+	}
+
+	got, err := fs.Abs("/foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "/c/foo" {
+		t.Errorf("Abs(%q) = %q, want %q", "/foo", got, "/c/foo")
+	}
+}
+
+func TestExtendedLengthPaths(t *testing.T) {
+	tests := []struct {
+		native, absfs string
+	}{
+		{`\\?\C:\very\long\path`, "/c/very/long/path"},
+		{`\\?\C:\`, "/c/"},
+		{`\\?\UNC\server\share\dir`, "//server/share/dir"},
+	}
+	for _, test := range tests {
+		if got := osfs.FromNative(test.native); got != test.absfs {
+			t.Errorf("FromNative(%q) = %q, want %q", test.native, got, test.absfs)
+		}
+	}
+
+	long := strings.Repeat("/component", 30)
+	extTests := []struct {
+		absfs, native string
+	}{
+		{"/c/short", `C:\short`},
+		{"/c" + long, `\\?\C:` + filepath.FromSlash(long)},
+		{"//server/share" + long, `\\?\UNC\server\share` + filepath.FromSlash(long)},
+		{"relative" + long, "relative" + filepath.FromSlash(long)},
+	}
+	for _, test := range extTests {
+		got := osfs.ToNativeExtended(test.absfs)
+		if got != test.native {
+			t.Errorf("ToNativeExtended(%q) = %q, want %q", test.absfs, got, test.native)
+		}
+		if back := osfs.FromNative(got); back != test.absfs {
+			t.Errorf("FromNative(ToNativeExtended(%q)) = %q", test.absfs, back)
+		}
+	}
+}
+
+func TestDriveRelativePaths(t *testing.T) {
+	tests := []struct {
+		native, absfs string
+	}{
+		{`C:\foo\bar`, "/c/foo/bar"},
+		{`C:foo\bar`, "c:foo/bar"},
+		{`C:`, "c:"},
+		{`C:\`, "/c/"},
+	}
+	for _, test := range tests {
+		if got := osfs.FromNative(test.native); got != test.absfs {
+			t.Errorf("FromNative(%q) = %q, want %q", test.native, got, test.absfs)
+		}
+		if got := osfs.ToNative(test.absfs); got != test.native {
+			t.Errorf("ToNative(%q) = %q, want %q", test.absfs, got, test.native)
+		}
+	}
+
+	fs, err := osfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := fs.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	vol := filepath.VolumeName(dir)
+	if len(vol) != 2 {
+		t.Skipf("working directory %q is not on a drive", dir)
+	}
+	got, err := fs.Abs(vol + "sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := osfs.FromNative(filepath.Join(dir, "sub")); got != want {
+		t.Errorf("Abs(%q) = %q, want %q", vol+"sub", got, want)
+	}
+}
+
+func TestInvalidUNCPath(t *testing.T) {
+	fs, err := osfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"//server", "//server/"} {
+		if _, err := fs.Stat(name); !errors.Is(err, osfs.ErrInvalidPath) {
+			t.Errorf("Stat(%q) error = %v, want ErrInvalidPath", name, err)
+		}
+	}
+}
+
+func TestAbsUNCInheritance(t *testing.T) {
+	dir := t.TempDir()
+	vol := filepath.VolumeName(dir)
+	if len(vol) != 2 {
+		t.Skipf("temporary directory %s is not on a drive", dir)
+	}
+	// Reach the temporary directory through the drive's administrative
+	// share, so the working directory is a UNC path.
+	unc := `\\localhost\` + vol[:1] + `$` + dir[len(vol):]
+	fs, err := osfs.NewFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Chdir(unc); err != nil {
+		t.Skip(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "file"), []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rooted := filepath.ToSlash(dir[len(vol):]) + "/file"
+	got, err := fs.Abs(rooted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "//localhost/" + vol[:1] + "$" + rooted; !strings.EqualFold(got, want) {
+		t.Errorf("Abs(%q) = %q, want %q", rooted, got, want)
+	}
+	f, err := fs.Open(rooted)
+	if err != nil {
+		t.Fatalf("Open(%q) with a UNC working directory: %v", rooted, err)
+	}
+	f.Close()
+}
@@ -0,0 +1,181 @@
+package osfs
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/absfs/absfs"
+)
+
+// NewPrefixMapper returns a view of base in which absolute absfs paths at or
+// below virtualPrefix are rewritten to the same paths below realPrefix, as a
+// bind mount would: with a virtual prefix of `/data` and a real prefix of
+// `/srv/app/data`, `/data/x` names `/srv/app/data/x` in base. Other paths,
+// including relative ones and `/database`, are passed through unchanged.
+// Both prefixes are cleaned with Clean, and paths are matched after
+// cleaning, by whole elements and case-sensitively on every platform.
+//
+// Getwd and Readlink return absfs paths and map those below realPrefix back
+// to virtualPrefix, so they can be passed back in, and Symlink rewrites an absolute target like
+// any other path. Names reported by opened Files and in errors are those of
+// base. The returned FileSystem also implements absfs.SymLinker; if base
+// does not, its symlink methods fail with absfs.ErrNotImplemented.
+func NewPrefixMapper(base absfs.FileSystem, virtualPrefix, realPrefix string) absfs.FileSystem {
+	return &prefixFS{base: base, virtual: Clean(virtualPrefix), real: Clean(realPrefix)}
+}
+
+type prefixFS struct {
+	base    absfs.FileSystem
+	virtual string
+	real    string
+}
+
+// trimPathPrefix returns the part of the clean path name below prefix,
+// which is empty or starts with a slash, and whether name is below prefix.
+func trimPathPrefix(name, prefix string) (string, bool) {
+	if name == prefix {
+		return "", true
+	}
+	if strings.HasSuffix(prefix, "/") {
+		// "/" or the root of a volume, like `/c/`
+		if strings.HasPrefix(name, prefix) {
+			return name[len(prefix)-1:], true
+		}
+		return "", false
+	}
+	if strings.HasPrefix(name, prefix+"/") {
+		return name[len(prefix):], true
+	}
+	return "", false
+}
+
+// swapPrefix replaces the prefix from of name with to, if name is below it.
+func swapPrefix(name, from, to string) string {
+	rest, ok := trimPathPrefix(Clean(name), from)
+	if !ok {
+		return name
+	}
+	if rest == "" {
+		return to
+	}
+	return strings.TrimSuffix(to, "/") + rest
+}
+
+func (m *prefixFS) path(name string) string {
+	if !IsAbs(name) {
+		return name
+	}
+	return swapPrefix(name, m.virtual, m.real)
+}
+
+func (m *prefixFS) unpath(name string) string {
+	if !IsAbs(name) {
+		return name
+	}
+	return swapPrefix(name, m.real, m.virtual)
+}
+
+func (m *prefixFS) OpenFile(name string, flag int, perm os.FileMode) (absfs.File, error) {
+	return m.base.OpenFile(m.path(name), flag, perm)
+}
+
+func (m *prefixFS) Open(name string) (absfs.File, error) {
+	return m.base.Open(m.path(name))
+}
+
+func (m *prefixFS) Create(name string) (absfs.File, error) {
+	return m.base.Create(m.path(name))
+}
+
+func (m *prefixFS) Mkdir(name string, perm os.FileMode) error {
+	return m.base.Mkdir(m.path(name), perm)
+}
+
+func (m *prefixFS) MkdirAll(name string, perm os.FileMode) error {
+	return m.base.MkdirAll(m.path(name), perm)
+}
+
+func (m *prefixFS) Remove(name string) error {
+	return m.base.Remove(m.path(name))
+}
+
+func (m *prefixFS) RemoveAll(name string) error {
+	return m.base.RemoveAll(m.path(name))
+}
+
+func (m *prefixFS) Rename(oldpath, newpath string) error {
+	return m.base.Rename(m.path(oldpath), m.path(newpath))
+}
+
+func (m *prefixFS) Stat(name string) (os.FileInfo, error) {
+	return m.base.Stat(m.path(name))
+}
+
+func (m *prefixFS) Chmod(name string, mode os.FileMode) error {
+	return m.base.Chmod(m.path(name), mode)
+}
+
+func (m *prefixFS) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	return m.base.Chtimes(m.path(name), atime, mtime)
+}
+
+func (m *prefixFS) Chown(name string, uid, gid int) error {
+	return m.base.Chown(m.path(name), uid, gid)
+}
+
+func (m *prefixFS) Truncate(name string, size int64) error {
+	return m.base.Truncate(m.path(name), size)
+}
+
+func (m *prefixFS) Chdir(dir string) error {
+	return m.base.Chdir(m.path(dir))
+}
+
+func (m *prefixFS) Getwd() (string, error) {
+	dir, err := m.base.Getwd()
+	if err != nil {
+		return dir, err
+	}
+	return m.unpath(FromNative(dir)), nil
+}
+
+func (m *prefixFS) Separator() uint8     { return m.base.Separator() }
+func (m *prefixFS) ListSeparator() uint8 { return m.base.ListSeparator() }
+func (m *prefixFS) TempDir() string      { return m.base.TempDir() }
+
+func (m *prefixFS) Lstat(name string) (os.FileInfo, error) {
+	sl, ok := m.base.(absfs.SymLinker)
+	if !ok {
+		return nil, &os.PathError{Op: "lstat", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	return sl.Lstat(m.path(name))
+}
+
+func (m *prefixFS) Lchown(name string, uid, gid int) error {
+	sl, ok := m.base.(absfs.SymLinker)
+	if !ok {
+		return &os.PathError{Op: "lchown", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	return sl.Lchown(m.path(name), uid, gid)
+}
+
+func (m *prefixFS) Readlink(name string) (string, error) {
+	sl, ok := m.base.(absfs.SymLinker)
+	if !ok {
+		return "", &os.PathError{Op: "readlink", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	target, err := sl.Readlink(m.path(name))
+	if err != nil {
+		return "", err
+	}
+	return m.unpath(FromNative(target)), nil
+}
+
+func (m *prefixFS) Symlink(oldname, newname string) error {
+	sl, ok := m.base.(absfs.SymLinker)
+	if !ok {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: absfs.ErrNotImplemented}
+	}
+	return sl.Symlink(m.path(oldname), m.path(newname))
+}
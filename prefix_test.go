@@ -0,0 +1,75 @@
+package osfs_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/osfs"
+)
+
+func TestPrefixMapper(t *testing.T) {
+	base, dir := newTempFS(t)
+	for _, name := range []string{"real/sub", "other"} {
+		if err := base.MkdirAll(name, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root := osfs.FromNative(dir)
+	fs := osfs.NewPrefixMapper(base, "/data/", root+"/real")
+
+	f, err := fs.Create("/data/sub/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if _, err := os.Stat(filepath.Join(dir, "real", "sub", "file")); err != nil {
+		t.Errorf("file created through the mapper is not below the real prefix: %v", err)
+	}
+	if _, err := fs.Stat("/data/./sub/../sub/file"); err != nil {
+		t.Errorf("Stat of an unclean mapped path: %v", err)
+	}
+	if _, err := fs.Stat("/data"); err != nil {
+		t.Errorf("Stat of the virtual prefix itself: %v", err)
+	}
+
+	// Paths outside the prefix pass through unchanged.
+	if err := fs.Mkdir(root+"/other/dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "other", "dir")); err != nil {
+		t.Error(err)
+	}
+	if _, err := fs.Stat("/database"); !os.IsNotExist(err) {
+		t.Errorf("Stat(/database) error = %v, want not exist", err)
+	}
+	if _, err := fs.Stat("other/dir"); err != nil {
+		t.Errorf("relative path: %v", err)
+	}
+
+	if err := fs.Rename("/data/sub/file", root+"/other/file"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "other", "file")); err != nil {
+		t.Errorf("Rename out of the prefix: %v", err)
+	}
+
+	if err := fs.Chdir("/data/sub"); err != nil {
+		t.Fatal(err)
+	}
+	if wd, err := fs.Getwd(); err != nil || wd != "/data/sub" {
+		t.Errorf("Getwd = %q, %v; want /data/sub", wd, err)
+	}
+
+	sl := fs.(absfs.SymLinker)
+	if err := sl.Symlink("/data/sub", root+"/other/link"); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if target, err := sl.Readlink(root + "/other/link"); err != nil || target != "/data/sub" {
+		t.Errorf("Readlink = %q, %v; want /data/sub", target, err)
+	}
+	if target, err := os.Readlink(filepath.Join(dir, "other", "link")); err != nil || target != filepath.Join(dir, "real", "sub") {
+		t.Errorf("link target on disk = %q, %v; want the real path", target, err)
+	}
+}
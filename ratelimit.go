@@ -0,0 +1,259 @@
+package osfs
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/absfs/absfs"
+)
+
+// tokenBucket paces events to rate per second, allowing bursts of up to a
+// tenth of a second's worth. A nil tokenBucket does not limit anything.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	burst := rate / 10
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{rate: rate, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// wait takes n tokens, sleeping until the bucket has refilled enough to
+// cover them. Tokens taken beyond those available are borrowed, so large
+// requests are not starved by small ones; later callers wait for the debt
+// to be repaid.
+func (b *tokenBucket) wait(n int) {
+	if b == nil || n <= 0 {
+		return
+	}
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	b.tokens -= float64(n)
+	var d time.Duration
+	if b.tokens < 0 {
+		d = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.mu.Unlock()
+	time.Sleep(d)
+}
+
+// RateLimited returns a FileSystem that forwards every call to base but
+// throttles them with token buckets: operations, that is every call on the
+// FileSystem and Readdir, Readdirnames, Stat, Truncate and Sync on opened
+// Files, to opsPerSec per second, and the data read from and written to
+// opened Files to bytesPerSec bytes per second. A limit of 0 or less
+// disables that bucket. Bursts of up to a tenth of a second's worth of
+// either are let through at once. Reads are charged for the bytes they
+// returned, after the fact, and writes for the bytes they are given, before
+// writing them.
+//
+// The limits are shared by all goroutines and Files using the returned
+// FileSystem, which also implements absfs.SymLinker; if base does not, its
+// symlink methods fail with absfs.ErrNotImplemented.
+func RateLimited(base absfs.FileSystem, bytesPerSec int64, opsPerSec int) absfs.FileSystem {
+	return &rateLimitedFS{
+		base:  base,
+		bytes: newTokenBucket(float64(bytesPerSec)),
+		ops:   newTokenBucket(float64(opsPerSec)),
+	}
+}
+
+type rateLimitedFS struct {
+	base  absfs.FileSystem
+	bytes *tokenBucket
+	ops   *tokenBucket
+}
+
+// file wraps a File returned by base.
+func (l *rateLimitedFS) file(f absfs.File, err error) (absfs.File, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &rateLimitedFile{File: f, l: l}, nil
+}
+
+func (l *rateLimitedFS) OpenFile(name string, flag int, perm os.FileMode) (absfs.File, error) {
+	l.ops.wait(1)
+	return l.file(l.base.OpenFile(name, flag, perm))
+}
+
+func (l *rateLimitedFS) Open(name string) (absfs.File, error) {
+	l.ops.wait(1)
+	return l.file(l.base.Open(name))
+}
+
+func (l *rateLimitedFS) Create(name string) (absfs.File, error) {
+	l.ops.wait(1)
+	return l.file(l.base.Create(name))
+}
+
+func (l *rateLimitedFS) Mkdir(name string, perm os.FileMode) error {
+	l.ops.wait(1)
+	return l.base.Mkdir(name, perm)
+}
+
+func (l *rateLimitedFS) MkdirAll(name string, perm os.FileMode) error {
+	l.ops.wait(1)
+	return l.base.MkdirAll(name, perm)
+}
+
+func (l *rateLimitedFS) Remove(name string) error {
+	l.ops.wait(1)
+	return l.base.Remove(name)
+}
+
+func (l *rateLimitedFS) RemoveAll(name string) error {
+	l.ops.wait(1)
+	return l.base.RemoveAll(name)
+}
+
+func (l *rateLimitedFS) Rename(oldpath, newpath string) error {
+	l.ops.wait(1)
+	return l.base.Rename(oldpath, newpath)
+}
+
+func (l *rateLimitedFS) Stat(name string) (os.FileInfo, error) {
+	l.ops.wait(1)
+	return l.base.Stat(name)
+}
+
+func (l *rateLimitedFS) Chmod(name string, mode os.FileMode) error {
+	l.ops.wait(1)
+	return l.base.Chmod(name, mode)
+}
+
+func (l *rateLimitedFS) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	l.ops.wait(1)
+	return l.base.Chtimes(name, atime, mtime)
+}
+
+func (l *rateLimitedFS) Chown(name string, uid, gid int) error {
+	l.ops.wait(1)
+	return l.base.Chown(name, uid, gid)
+}
+
+func (l *rateLimitedFS) Truncate(name string, size int64) error {
+	l.ops.wait(1)
+	return l.base.Truncate(name, size)
+}
+
+func (l *rateLimitedFS) Chdir(dir string) error {
+	l.ops.wait(1)
+	return l.base.Chdir(dir)
+}
+
+func (l *rateLimitedFS) Getwd() (string, error) { return l.base.Getwd() }
+func (l *rateLimitedFS) Separator() uint8       { return l.base.Separator() }
+func (l *rateLimitedFS) ListSeparator() uint8   { return l.base.ListSeparator() }
+func (l *rateLimitedFS) TempDir() string        { return l.base.TempDir() }
+
+func (l *rateLimitedFS) Lstat(name string) (os.FileInfo, error) {
+	sl, ok := l.base.(absfs.SymLinker)
+	if !ok {
+		return nil, &os.PathError{Op: "lstat", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	l.ops.wait(1)
+	return sl.Lstat(name)
+}
+
+func (l *rateLimitedFS) Lchown(name string, uid, gid int) error {
+	sl, ok := l.base.(absfs.SymLinker)
+	if !ok {
+		return &os.PathError{Op: "lchown", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	l.ops.wait(1)
+	return sl.Lchown(name, uid, gid)
+}
+
+func (l *rateLimitedFS) Readlink(name string) (string, error) {
+	sl, ok := l.base.(absfs.SymLinker)
+	if !ok {
+		return "", &os.PathError{Op: "readlink", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	l.ops.wait(1)
+	return sl.Readlink(name)
+}
+
+func (l *rateLimitedFS) Symlink(oldname, newname string) error {
+	sl, ok := l.base.(absfs.SymLinker)
+	if !ok {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: absfs.ErrNotImplemented}
+	}
+	l.ops.wait(1)
+	return sl.Symlink(oldname, newname)
+}
+
+// rateLimitedFile throttles the calls on a File opened through a
+// rateLimitedFS.
+type rateLimitedFile struct {
+	absfs.File
+	l *rateLimitedFS
+}
+
+func (f *rateLimitedFile) Read(b []byte) (int, error) {
+	n, err := f.File.Read(b)
+	f.l.bytes.wait(n)
+	return n, err
+}
+
+func (f *rateLimitedFile) ReadAt(b []byte, off int64) (int, error) {
+	n, err := f.File.ReadAt(b, off)
+	f.l.bytes.wait(n)
+	return n, err
+}
+
+func (f *rateLimitedFile) Write(b []byte) (int, error) {
+	f.l.bytes.wait(len(b))
+	return f.File.Write(b)
+}
+
+func (f *rateLimitedFile) WriteAt(b []byte, off int64) (int, error) {
+	f.l.bytes.wait(len(b))
+	return f.File.WriteAt(b, off)
+}
+
+func (f *rateLimitedFile) WriteString(s string) (int, error) {
+	f.l.bytes.wait(len(s))
+	return f.File.WriteString(s)
+}
+
+func (f *rateLimitedFile) Readdir(n int) ([]os.FileInfo, error) {
+	f.l.ops.wait(1)
+	return f.File.Readdir(n)
+}
+
+func (f *rateLimitedFile) Readdirnames(n int) ([]string, error) {
+	f.l.ops.wait(1)
+	return f.File.Readdirnames(n)
+}
+
+func (f *rateLimitedFile) Stat() (os.FileInfo, error) {
+	f.l.ops.wait(1)
+	return f.File.Stat()
+}
+
+func (f *rateLimitedFile) Truncate(size int64) error {
+	f.l.ops.wait(1)
+	return f.File.Truncate(size)
+}
+
+func (f *rateLimitedFile) Sync() error {
+	f.l.ops.wait(1)
+	return f.File.Sync()
+}
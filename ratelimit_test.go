@@ -0,0 +1,62 @@
+package osfs_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/absfs/osfs"
+)
+
+func TestRateLimitedBytes(t *testing.T) {
+	base, _ := newTempFS(t)
+	data := bytes.Repeat([]byte("x"), 256<<10)
+	if err := base.WriteFile("file", data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	const rate = 1 << 20
+	fs := osfs.RateLimited(base, rate, 0)
+	f, err := fs.Open("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	start := time.Now()
+	got, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	elapsed := time.Since(start)
+	if !bytes.Equal(got, data) {
+		t.Fatal("data read through the limiter differs")
+	}
+	// Everything but the initial burst of a tenth of a second's worth is
+	// paced.
+	min := time.Duration(float64(len(data)-rate/10) / rate * float64(time.Second))
+	if elapsed < min {
+		t.Errorf("reading %d bytes at %d B/s took %v, want at least %v", len(data), rate, elapsed, min)
+	}
+}
+
+func TestRateLimitedOps(t *testing.T) {
+	base, _ := newTempFS(t)
+	if err := base.WriteFile("file", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	const rate = 100
+	fs := osfs.RateLimited(base, 0, rate)
+	start := time.Now()
+	for i := 0; i < 30; i++ {
+		if _, err := fs.Stat("file"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The burst is 10 operations; the other 20 take 10ms each.
+	if elapsed, min := time.Since(start), 200*time.Millisecond; elapsed < min {
+		t.Errorf("30 Stats at %d/s took %v, want at least %v", rate, elapsed, min)
+	}
+}
@@ -0,0 +1,67 @@
+package osfs
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// rangeDirBatch is the number of entries RangeDir reads at a time.
+const rangeDirBatch = 256
+
+// RangeDir calls fn for each entry of the named directory, in the order the
+// filesystem returns them, reading the directory in small batches so that
+// memory use does not grow with its size. "." and ".." are skipped. If fn
+// returns filepath.SkipDir (or fs.SkipDir, the same value) the iteration
+// stops and RangeDir returns nil; any other error stops it and is returned.
+func (fs *FileSystem) RangeDir(name string, fn func(os.DirEntry) error) error {
+	path, err := fs.fixPathErr("open", name)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for {
+		entries, err := f.ReadDir(rangeDirBatch)
+		for _, e := range entries {
+			if ferr := fn(fs.normalizeEntry(e)); ferr != nil {
+				if ferr == filepath.SkipDir {
+					return nil
+				}
+				return ferr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// ReadDirUnsorted reads the named directory and returns its entries like
+// os.ReadDir, but in the order the filesystem returns them instead of
+// sorted by name. That order is platform and filesystem dependent and may
+// change between calls; skipping the sort saves time on very large
+// directories when the caller does not need it.
+func (fs *FileSystem) ReadDirUnsorted(name string) ([]os.DirEntry, error) {
+	path, err := fs.fixPathErr("open", name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries, err := f.ReadDir(-1)
+	for i, e := range entries {
+		entries[i] = fs.normalizeEntry(e)
+	}
+	return entries, err
+}
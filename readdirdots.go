@@ -0,0 +1,44 @@
+package osfs
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ReadDirDots reads the named directory like os.ReadDir, but starts the
+// listing with the "." and ".." entries, which os.ReadDir leaves out. Both
+// are reported as directories; their Info describes the directory itself
+// and its parent. The other entries are sorted by name.
+func (fs *FileSystem) ReadDirDots(name string) ([]os.DirEntry, error) {
+	dir, err := fs.fixPathErr("open", name)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]os.DirEntry, 0, len(entries)+2)
+	for _, dot := range []string{".", ".."} {
+		info, err := os.Stat(filepath.Join(dir, dot))
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, dotEntry{dot, info})
+	}
+	for _, e := range entries {
+		list = append(list, fs.normalizeEntry(e))
+	}
+	return list, nil
+}
+
+// dotEntry is the "." or ".." entry of a directory.
+type dotEntry struct {
+	name string
+	info os.FileInfo
+}
+
+func (d dotEntry) Name() string               { return d.name }
+func (d dotEntry) IsDir() bool                { return true }
+func (d dotEntry) Type() os.FileMode          { return os.ModeDir }
+func (d dotEntry) Info() (os.FileInfo, error) { return d.info, nil }
@@ -0,0 +1,142 @@
+package osfs
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/absfs/absfs"
+)
+
+// ErrReadOnly is returned, wrapped in an *os.PathError or *os.LinkError, by
+// every mutating operation of a FileSystem returned by ReadOnly.
+var ErrReadOnly = errors.New("read-only file system")
+
+// ReadOnly returns a view of base that passes reads through and rejects
+// every operation that could modify it with ErrReadOnly: creating, removing
+// and renaming entries, changing attributes, truncating, creating symbolic
+// links and opening files with any of os.O_WRONLY, os.O_RDWR, os.O_CREATE,
+// os.O_TRUNC or os.O_APPEND. Files opened through the view refuse writes as
+// well. The returned FileSystem also implements absfs.SymLinker.
+func ReadOnly(base absfs.FileSystem) absfs.FileSystem {
+	return &readOnlyFS{base}
+}
+
+type readOnlyFS struct {
+	base absfs.FileSystem
+}
+
+func readOnlyError(op, name string) error {
+	return &os.PathError{Op: op, Path: name, Err: ErrReadOnly}
+}
+
+func (r *readOnlyFS) OpenFile(name string, flag int, perm os.FileMode) (absfs.File, error) {
+	if flag&writeFlags != 0 {
+		return nil, readOnlyError("open", name)
+	}
+	f, err := r.base.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &readOnlyFile{f}, nil
+}
+
+func (r *readOnlyFS) Open(name string) (absfs.File, error) {
+	f, err := r.base.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &readOnlyFile{f}, nil
+}
+
+func (r *readOnlyFS) Create(name string) (absfs.File, error) {
+	return nil, readOnlyError("create", name)
+}
+
+func (r *readOnlyFS) Mkdir(name string, perm os.FileMode) error {
+	return readOnlyError("mkdir", name)
+}
+
+func (r *readOnlyFS) MkdirAll(name string, perm os.FileMode) error {
+	return readOnlyError("mkdir", name)
+}
+
+func (r *readOnlyFS) Remove(name string) error {
+	return readOnlyError("remove", name)
+}
+
+func (r *readOnlyFS) RemoveAll(name string) error {
+	return readOnlyError("removeall", name)
+}
+
+func (r *readOnlyFS) Rename(oldpath, newpath string) error {
+	return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: ErrReadOnly}
+}
+
+func (r *readOnlyFS) Chmod(name string, mode os.FileMode) error {
+	return readOnlyError("chmod", name)
+}
+
+func (r *readOnlyFS) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	return readOnlyError("chtimes", name)
+}
+
+func (r *readOnlyFS) Chown(name string, uid, gid int) error {
+	return readOnlyError("chown", name)
+}
+
+func (r *readOnlyFS) Truncate(name string, size int64) error {
+	return readOnlyError("truncate", name)
+}
+
+func (r *readOnlyFS) Stat(name string) (os.FileInfo, error) { return r.base.Stat(name) }
+func (r *readOnlyFS) Separator() uint8                      { return r.base.Separator() }
+func (r *readOnlyFS) ListSeparator() uint8                  { return r.base.ListSeparator() }
+func (r *readOnlyFS) Chdir(dir string) error                { return r.base.Chdir(dir) }
+func (r *readOnlyFS) Getwd() (string, error)                { return r.base.Getwd() }
+func (r *readOnlyFS) TempDir() string                       { return r.base.TempDir() }
+
+func (r *readOnlyFS) Lstat(name string) (os.FileInfo, error) {
+	sl, ok := r.base.(absfs.SymLinker)
+	if !ok {
+		return nil, &os.PathError{Op: "lstat", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	return sl.Lstat(name)
+}
+
+func (r *readOnlyFS) Readlink(name string) (string, error) {
+	sl, ok := r.base.(absfs.SymLinker)
+	if !ok {
+		return "", &os.PathError{Op: "readlink", Path: name, Err: absfs.ErrNotImplemented}
+	}
+	return sl.Readlink(name)
+}
+
+func (r *readOnlyFS) Lchown(name string, uid, gid int) error {
+	return readOnlyError("lchown", name)
+}
+
+func (r *readOnlyFS) Symlink(oldname, newname string) error {
+	return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: ErrReadOnly}
+}
+
+// readOnlyFile refuses writes through a File opened by a readOnlyFS.
+type readOnlyFile struct {
+	absfs.File
+}
+
+func (f *readOnlyFile) Write(b []byte) (int, error) {
+	return 0, readOnlyError("write", f.Name())
+}
+
+func (f *readOnlyFile) WriteAt(b []byte, off int64) (int, error) {
+	return 0, readOnlyError("write", f.Name())
+}
+
+func (f *readOnlyFile) WriteString(s string) (int, error) {
+	return 0, readOnlyError("write", f.Name())
+}
+
+func (f *readOnlyFile) Truncate(size int64) error {
+	return readOnlyError("truncate", f.Name())
+}
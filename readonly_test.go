@@ -0,0 +1,84 @@
+package osfs_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/absfs/absfs"
+	"github.com/absfs/osfs"
+)
+
+func TestReadOnly(t *testing.T) {
+	base, _ := newTempFS(t)
+	if err := base.Mkdir("dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := base.WriteFile("file", []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := osfs.ReadOnly(base)
+	sl, ok := fs.(absfs.SymLinker)
+	if !ok {
+		t.Fatal("read-only FileSystem does not implement absfs.SymLinker")
+	}
+
+	f, err := fs.Open("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil || string(data) != "data" {
+		t.Errorf("reading through the read-only view: %q, %v", data, err)
+	}
+	if _, err := fs.Stat("dir"); err != nil {
+		t.Errorf("Stat: %v", err)
+	}
+	if _, err := sl.Lstat("file"); err != nil {
+		t.Errorf("Lstat: %v", err)
+	}
+
+	now := time.Now()
+	mutations := map[string]func() error{
+		"Create":           func() error { _, err := fs.Create("new"); return err },
+		"Mkdir":            func() error { return fs.Mkdir("new", 0755) },
+		"MkdirAll":         func() error { return fs.MkdirAll("new/sub", 0755) },
+		"Remove":           func() error { return fs.Remove("file") },
+		"RemoveAll":        func() error { return fs.RemoveAll("dir") },
+		"Rename":           func() error { return fs.Rename("file", "new") },
+		"Chmod":            func() error { return fs.Chmod("file", 0600) },
+		"Chtimes":          func() error { return fs.Chtimes("file", now, now) },
+		"Chown":            func() error { return fs.Chown("file", 0, 0) },
+		"Truncate":         func() error { return fs.Truncate("file", 0) },
+		"Symlink":          func() error { return sl.Symlink("file", "link") },
+		"Lchown":           func() error { return sl.Lchown("file", 0, 0) },
+		"File.Write":       func() error { _, err := f.Write([]byte("x")); return err },
+		"File.WriteAt":     func() error { _, err := f.WriteAt([]byte("x"), 0); return err },
+		"File.WriteString": func() error { _, err := f.WriteString("x"); return err },
+		"File.Truncate":    func() error { return f.Truncate(0) },
+	}
+	for _, flag := range []int{os.O_WRONLY, os.O_RDWR, os.O_CREATE, os.O_TRUNC, os.O_APPEND} {
+		flag := flag
+		mutations["OpenFile"+fmt.Sprint(flag)] = func() error {
+			_, err := fs.OpenFile("file", flag, 0644)
+			return err
+		}
+	}
+	for name, mutate := range mutations {
+		if err := mutate(); !errors.Is(err, osfs.ErrReadOnly) {
+			t.Errorf("%s: err = %v, want %v", name, err, osfs.ErrReadOnly)
+		}
+	}
+	f.Close()
+
+	if data, err := base.ReadFileInto("file", nil); err != nil || string(data) != "data" {
+		t.Errorf("file changed to %q, %v", data, err)
+	}
+	if base.Exists("new") || base.Exists("link") || !base.Exists("dir") {
+		t.Error("the read-only view modified the tree")
+	}
+}
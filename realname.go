@@ -0,0 +1,65 @@
+package osfs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RealName returns the absolute absfs path of name with every element
+// spelled as it is stored on disk. Each element is looked up in a listing
+// of its parent directory, preferring an exact match and otherwise taking
+// the first entry that matches ignoring case, so on case-insensitive
+// filesystems differently cased spellings of a path all map to the same
+// key. If an element has no match at all, an error wrapping
+// os.ErrNotExist is returned. Symbolic links are not resolved, and the
+// volume name is kept as given.
+func (fs *FileSystem) RealName(name string) (string, error) {
+	path, err := fs.fixPathErr("realname", name)
+	if err != nil {
+		return "", err
+	}
+	path = filepath.Clean(path)
+	vol := filepath.VolumeName(path)
+	real := vol + string(filepath.Separator)
+
+	for _, elem := range strings.Split(path[len(vol):], string(filepath.Separator)) {
+		if elem == "" {
+			continue
+		}
+		found, err := realElem(real, elem)
+		if err != nil {
+			return "", err
+		}
+		real = filepath.Join(real, found)
+	}
+	return FromNative(real), nil
+}
+
+// realElem returns the entry of the native directory dir that matches elem
+// exactly or, failing that, ignoring case.
+func realElem(dir, elem string) (string, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	names, err := f.Readdirnames(-1)
+	if err != nil {
+		return "", err
+	}
+
+	fold := ""
+	for _, n := range names {
+		if n == elem {
+			return n, nil
+		}
+		if fold == "" && strings.EqualFold(n, elem) {
+			fold = n
+		}
+	}
+	if fold == "" {
+		return "", &os.PathError{Op: "realname", Path: filepath.Join(dir, elem), Err: os.ErrNotExist}
+	}
+	return fold, nil
+}
@@ -0,0 +1,44 @@
+package osfs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// ErrSymlinkInPath is returned, wrapped in an *os.PathError, by
+// RemoveTreeSecure when a component of the path it is given is a symbolic
+// link.
+var ErrSymlinkInPath = errors.New("path contains a symbolic link")
+
+// RemoveTreeSecure removes name and any children it contains, like
+// RemoveAll, but refuses with ErrSymlinkInPath if name or any directory
+// leading to it is a symbolic link. Paths that pass through links, such as
+// /tmp on macOS, should be resolved with filepath.EvalSymlinks first. As
+// with RemoveAll, a name that does not exist is not an error.
+//
+// Every step is made relative to an open directory: the path is opened one
+// component at a time with O_NOFOLLOW, directories in the tree are opened
+// with openat(2) and O_NOFOLLOW, and entries are removed with unlinkat(2)
+// on their parent's descriptor. Replacing a directory that was already
+// opened with a symbolic link therefore cannot redirect the removal, and a
+// directory replaced before it is opened makes RemoveTreeSecure fail rather
+// than follow the link. Symbolic links inside the tree are removed, never
+// followed. The guarantee covers where removals happen, not what is
+// removed: entries created in the tree while it is being removed may be
+// removed too, and RemoveTreeSecure fails if a directory it has emptied
+// gains new entries before it is removed.
+//
+// RemoveTreeSecure is implemented on Unix systems; elsewhere it returns an
+// error wrapping ErrNotSupported.
+func (fs *FileSystem) RemoveTreeSecure(name string) error {
+	path, err := fs.fixPathErr("RemoveTreeSecure", name)
+	if err != nil {
+		return err
+	}
+	path = filepath.Clean(path)
+	if base := filepath.Base(name); base == "." || path == filepath.Dir(path) {
+		return &os.PathError{Op: "RemoveTreeSecure", Path: name, Err: os.ErrInvalid}
+	}
+	return removeTreeSecure(path)
+}
@@ -0,0 +1,10 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package osfs
+
+import "os"
+
+func removeTreeSecure(path string) error {
+	return &os.PathError{Op: "RemoveTreeSecure", Path: path, Err: ErrNotSupported}
+}
@@ -0,0 +1,125 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package osfs
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// removeTreeSecure removes the clean, absolute path, walking to its parent
+// one directory descriptor at a time.
+func removeTreeSecure(path string) error {
+	dir, err := unix.Open("/", unix.O_RDONLY|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return &os.PathError{Op: "open", Path: "/", Err: err}
+	}
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	cur := "/"
+	for _, part := range parts[:len(parts)-1] {
+		cur = filepath.Join(cur, part)
+		fd, err := openDirAt(dir, part)
+		unix.Close(dir)
+		if err != nil {
+			if err == unix.ENOENT {
+				return nil
+			}
+			return &os.PathError{Op: "openat", Path: cur, Err: err}
+		}
+		dir = fd
+	}
+	defer unix.Close(dir)
+	return removeAt(dir, path, parts[len(parts)-1], true)
+}
+
+// openDirAt opens the directory name in dir without following a symbolic
+// link, failing with ErrSymlinkInPath if name is one.
+func openDirAt(dir int, name string) (int, error) {
+	fd, err := unix.Openat(dir, name, unix.O_RDONLY|unix.O_DIRECTORY|unix.O_NOFOLLOW|unix.O_CLOEXEC, 0)
+	if err == nil {
+		return fd, nil
+	}
+	// Systems differ in reporting a link with ELOOP, ENOTDIR or EMLINK.
+	var st unix.Stat_t
+	if unix.Fstatat(dir, name, &st, unix.AT_SYMLINK_NOFOLLOW) == nil && st.Mode&unix.S_IFMT == unix.S_IFLNK {
+		return -1, ErrSymlinkInPath
+	}
+	return -1, err
+}
+
+// removeAt removes the entry name of dir, whose full path is path, and its
+// children. The root of the removal must not be a symbolic link.
+func removeAt(dir int, path, name string, root bool) error {
+	var st unix.Stat_t
+	if err := unix.Fstatat(dir, name, &st, unix.AT_SYMLINK_NOFOLLOW); err != nil {
+		if err == unix.ENOENT {
+			return nil
+		}
+		return &os.PathError{Op: "fstatat", Path: path, Err: err}
+	}
+	switch st.Mode & unix.S_IFMT {
+	case unix.S_IFLNK:
+		if root {
+			return &os.PathError{Op: "RemoveTreeSecure", Path: path, Err: ErrSymlinkInPath}
+		}
+	case unix.S_IFDIR:
+		fd, err := openDirAt(dir, name)
+		if err != nil {
+			if err == unix.ENOENT {
+				return nil
+			}
+			return &os.PathError{Op: "openat", Path: path, Err: err}
+		}
+		err = removeEntries(fd, path)
+		unix.Close(fd)
+		if err != nil {
+			return err
+		}
+		if err := unix.Unlinkat(dir, name, unix.AT_REMOVEDIR); err != nil && err != unix.ENOENT {
+			return &os.PathError{Op: "unlinkat", Path: path, Err: err}
+		}
+		return nil
+	}
+	if err := unix.Unlinkat(dir, name, 0); err != nil && err != unix.ENOENT {
+		return &os.PathError{Op: "unlinkat", Path: path, Err: err}
+	}
+	return nil
+}
+
+// removeEntries removes the children of the open directory dir, whose full
+// path is path.
+func removeEntries(dir int, path string) error {
+	for {
+		// Read from the start for every batch, as removing entries while
+		// reading can make the read position skip some. The duplicate
+		// shares the read position of dir.
+		fd, err := unix.Dup(dir)
+		if err != nil {
+			return &os.PathError{Op: "dup", Path: path, Err: err}
+		}
+		unix.CloseOnExec(fd)
+		if _, err := unix.Seek(fd, 0, io.SeekStart); err != nil {
+			unix.Close(fd)
+			return &os.PathError{Op: "seek", Path: path, Err: err}
+		}
+		f := os.NewFile(uintptr(fd), path)
+		names, err := f.Readdirnames(rangeDirBatch)
+		f.Close()
+		for _, name := range names {
+			if err := removeAt(dir, filepath.Join(path, name), name, false); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF || len(names) < rangeDirBatch {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,26 @@
+//go:build !windows
+// +build !windows
+
+package osfs
+
+import "os"
+
+// replaceFile renames oldpath to newpath, atomically replacing newpath if it
+// exists.
+func replaceFile(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+// syncDir flushes the directory dir, making renames and creations within it
+// durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if err1 := d.Close(); err == nil {
+		err = err1
+	}
+	return err
+}
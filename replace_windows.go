@@ -0,0 +1,30 @@
+package osfs
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// replaceFile renames oldpath to newpath, replacing newpath if it exists,
+// and returns only once the move has been written to disk.
+func replaceFile(oldpath, newpath string) error {
+	from, err := windows.UTF16PtrFromString(oldpath)
+	if err != nil {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: err}
+	}
+	to, err := windows.UTF16PtrFromString(newpath)
+	if err != nil {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: err}
+	}
+	if err := windows.MoveFileEx(from, to, windows.MOVEFILE_REPLACE_EXISTING|windows.MOVEFILE_WRITE_THROUGH); err != nil {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: err}
+	}
+	return nil
+}
+
+// syncDir is a no-op: directories cannot be flushed on Windows, and
+// MOVEFILE_WRITE_THROUGH already made the rename durable.
+func syncDir(dir string) error {
+	return nil
+}
@@ -0,0 +1,128 @@
+package osfs
+
+import (
+	iofs "io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/absfs/absfs"
+)
+
+// Root provides access to the files in one directory tree. Unlike Chroot,
+// which confines paths by resolving them as strings, a Root is backed by an
+// open handle to its directory and walks every path relative to it with
+// os.Root, so neither `..` nor a symbolic link can lead out of the tree,
+// even while others modify it.
+//
+// Names passed to a Root use forward slashes and are relative to the root;
+// a leading slash is ignored, so "/a/b" and "a/b" name the same file. A Root
+// must be closed when no longer needed.
+type Root struct {
+	fs   *FileSystem
+	name string
+	h    rootHandle
+}
+
+// rootHandle is the subset of os.Root used by Root.
+type rootHandle interface {
+	Close() error
+	OpenFile(name string, flag int, perm os.FileMode) (*os.File, error)
+	Mkdir(name string, perm os.FileMode) error
+	Remove(name string) error
+	Stat(name string) (os.FileInfo, error)
+	Lstat(name string) (os.FileInfo, error)
+	FS() iofs.FS
+	sub(name string) (rootHandle, error)
+}
+
+// OpenRoot opens the named directory as a Root. It returns an error wrapping
+// ErrNotSupported when built with a Go release that lacks os.Root.
+func (fs *FileSystem) OpenRoot(name string) (*Root, error) {
+	dir, err := fs.fixPathErr("openroot", name)
+	if err != nil {
+		return nil, err
+	}
+	h, err := openRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Root{fs: fs, name: FromNative(dir), h: h}, nil
+}
+
+// rootPath converts a name relative to a Root to the native relative form.
+func rootPath(name string) string {
+	name = strings.TrimLeft(name, "/")
+	if name == "" {
+		return "."
+	}
+	return filepath.FromSlash(name)
+}
+
+// Name returns the absfs path of the root directory.
+func (r *Root) Name() string {
+	return r.name
+}
+
+// Close closes the Root. Files opened through it remain open.
+func (r *Root) Close() error {
+	return r.h.Close()
+}
+
+// Open opens the named file in the root for reading.
+func (r *Root) Open(name string) (absfs.File, error) {
+	return r.OpenFile(name, os.O_RDONLY, 0)
+}
+
+// Create creates or truncates the named file in the root.
+func (r *Root) Create(name string) (absfs.File, error) {
+	return r.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+}
+
+// OpenFile opens the named file in the root with the given flag and perm.
+// If the final element is a symbolic link, it is followed only if its
+// target lies within the root.
+func (r *Root) OpenFile(name string, flag int, perm os.FileMode) (absfs.File, error) {
+	f, err := r.h.OpenFile(rootPath(name), flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &File{r.fs, f}, nil
+}
+
+// OpenRoot opens the named directory in the root as a Root of its own.
+func (r *Root) OpenRoot(name string) (*Root, error) {
+	h, err := r.h.sub(rootPath(name))
+	if err != nil {
+		return nil, err
+	}
+	return &Root{fs: r.fs, name: path.Join(r.name, path.Clean("/"+name)), h: h}, nil
+}
+
+// Mkdir creates a new directory in the root with mode perm (before umask).
+func (r *Root) Mkdir(name string, perm os.FileMode) error {
+	return r.h.Mkdir(rootPath(name), perm)
+}
+
+// Remove removes the named file or empty directory in the root.
+func (r *Root) Remove(name string) error {
+	return r.h.Remove(rootPath(name))
+}
+
+// Stat returns a FileInfo describing the named file in the root, following
+// symbolic links that stay within the root.
+func (r *Root) Stat(name string) (os.FileInfo, error) {
+	return r.h.Stat(rootPath(name))
+}
+
+// Lstat returns a FileInfo describing the named file in the root without
+// following a final symbolic link.
+func (r *Root) Lstat(name string) (os.FileInfo, error) {
+	return r.h.Lstat(rootPath(name))
+}
+
+// FS returns an io/fs.FS for the files in the root.
+func (r *Root) FS() iofs.FS {
+	return r.h.FS()
+}
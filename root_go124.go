@@ -0,0 +1,26 @@
+//go:build go1.24
+// +build go1.24
+
+package osfs
+
+import "os"
+
+type osRoot struct {
+	*os.Root
+}
+
+func openRoot(dir string) (rootHandle, error) {
+	r, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	return osRoot{r}, nil
+}
+
+func (r osRoot) sub(name string) (rootHandle, error) {
+	s, err := r.OpenRoot(name)
+	if err != nil {
+		return nil, err
+	}
+	return osRoot{s}, nil
+}
@@ -0,0 +1,10 @@
+//go:build !go1.24
+// +build !go1.24
+
+package osfs
+
+import "os"
+
+func openRoot(dir string) (rootHandle, error) {
+	return nil, &os.PathError{Op: "openroot", Path: dir, Err: ErrNotSupported}
+}
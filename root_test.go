@@ -0,0 +1,83 @@
+package osfs_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/absfs/osfs"
+)
+
+func TestOpenRoot(t *testing.T) {
+	fs, dir := newTempFS(t)
+
+	if err := fs.MkdirAll("top/sub", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile("secret", []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "secret"), filepath.Join(dir, "top", "escape")); err != nil {
+		t.Skipf("symlink: %v", err)
+	}
+	if err := os.Symlink("sub", filepath.Join(dir, "top", "inside")); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := fs.OpenRoot("top")
+	if err != nil {
+		if errors.Is(err, osfs.ErrNotSupported) {
+			t.Skip(err)
+		}
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	f, err := r.Create("/sub/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(f, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	info, err := r.Stat("inside/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != 5 {
+		t.Errorf("inside/file size = %d, want 5", info.Size())
+	}
+	if info, err := r.Lstat("escape"); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("Lstat(escape) = %v, %v; want a symlink", info, err)
+	}
+
+	for _, name := range []string{"escape", "../secret", "sub/../../secret"} {
+		if f, err := r.Open(name); err == nil {
+			f.Close()
+			t.Errorf("Open(%q) succeeded, want an error", name)
+		}
+	}
+
+	sub, err := r.OpenRoot("sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	if want := path.Join(r.Name(), "sub"); sub.Name() != want {
+		t.Errorf("sub.Name() = %q, want %q", sub.Name(), want)
+	}
+	if _, err := sub.Stat("file"); err != nil {
+		t.Error(err)
+	}
+	if err := sub.Remove("file"); err != nil {
+		t.Error(err)
+	}
+	if fs.Exists("top/sub/file") {
+		t.Error("file still exists after Remove")
+	}
+}
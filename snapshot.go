@@ -0,0 +1,123 @@
+package osfs
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// SnapshotEntry records the metadata of one entry of a Snapshot.
+type SnapshotEntry struct {
+	// Path is the slash separated path of the entry relative to the root.
+	Path string
+
+	// Mode holds the type and permission bits of the entry.
+	Mode    os.FileMode
+	Size    int64
+	ModTime time.Time
+}
+
+// Snapshot is the recorded metadata of a directory tree, as taken by
+// SnapshotTree. Entries are sorted by Path and times are kept in UTC, so two
+// snapshots of an unchanged tree are equal and serialize identically.
+type Snapshot struct {
+	Entries []SnapshotEntry
+}
+
+// Changes lists the slash separated paths, relative to the root, that differ
+// between a Snapshot and the current state of the tree. Each list is sorted.
+type Changes struct {
+	Added    []string
+	Removed  []string
+	Modified []string
+}
+
+// Empty reports whether c records no changes.
+func (c Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Modified) == 0
+}
+
+// SnapshotTree records the path, type, size, mode and modification time of
+// every entry below root, root itself excluded. Symbolic links are recorded
+// but not followed. Nothing is read from regular files, so a snapshot is
+// cheap, but a change that keeps both size and modification time is not
+// noticed.
+func (fs *FileSystem) SnapshotTree(root string) (Snapshot, error) {
+	root, err := fs.fixPathErr("open", root)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	var s Snapshot
+	err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		s.Entries = append(s.Entries, SnapshotEntry{
+			Path:    filepath.ToSlash(rel),
+			Mode:    info.Mode(),
+			Size:    info.Size(),
+			ModTime: info.ModTime().UTC(),
+		})
+		return nil
+	})
+	if err != nil {
+		return Snapshot{}, err
+	}
+	sort.Slice(s.Entries, func(i, j int) bool { return s.Entries[i].Path < s.Entries[j].Path })
+	return s, nil
+}
+
+// CompareSnapshot takes a new snapshot of root and reports how it differs
+// from s. A file is modified when its mode, size or modification time
+// changed; a directory only when its mode changed, since its size and time
+// follow from changes to its entries, which are reported themselves.
+func (fs *FileSystem) CompareSnapshot(root string, s Snapshot) (Changes, error) {
+	cur, err := fs.SnapshotTree(root)
+	if err != nil {
+		return Changes{}, err
+	}
+
+	var c Changes
+	old, now := s.Entries, cur.Entries
+	for len(old) > 0 || len(now) > 0 {
+		switch {
+		case len(now) == 0 || len(old) > 0 && old[0].Path < now[0].Path:
+			c.Removed = append(c.Removed, old[0].Path)
+			old = old[1:]
+		case len(old) == 0 || now[0].Path < old[0].Path:
+			c.Added = append(c.Added, now[0].Path)
+			now = now[1:]
+		default:
+			if old[0].changed(now[0]) {
+				c.Modified = append(c.Modified, now[0].Path)
+			}
+			old, now = old[1:], now[1:]
+		}
+	}
+	return c, nil
+}
+
+func (e SnapshotEntry) changed(n SnapshotEntry) bool {
+	if e.Mode != n.Mode {
+		return true
+	}
+	if e.Mode.IsDir() {
+		return false
+	}
+	return e.Size != n.Size || !e.ModTime.Equal(n.ModTime)
+}
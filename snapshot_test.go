@@ -0,0 +1,79 @@
+package osfs_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/absfs/osfs"
+)
+
+func TestSnapshotTree(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	for _, dir := range []string{"root/a", "root/b"} {
+		if err := fs.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"root/a/keep", "root/a/edit", "root/b/gone", "root/touch"} {
+		if err := fs.WriteFile(name, []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err := fs.SnapshotTree("root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Entries) != 6 {
+		t.Fatalf("snapshot has %d entries, want 6: %v", len(s.Entries), s.Entries)
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded osfs.Snapshot
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	c, err := fs.CompareSnapshot("root", decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Empty() {
+		t.Errorf("unchanged tree reported changes: %+v", c)
+	}
+
+	if err := fs.WriteFile("root/a/edit", []byte("longer data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Hour)
+	if err := fs.Chtimes("root/touch", later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Remove("root/b/gone"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Mkdir("root/c", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile("root/c/new", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err = fs.CompareSnapshot("root", decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := osfs.Changes{
+		Added:    []string{"c", "c/new"},
+		Removed:  []string{"b/gone"},
+		Modified: []string{"a/edit", "touch"},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("CompareSnapshot = %+v, want %+v", c, want)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build dragonfly || linux || openbsd || solaris
+// +build dragonfly linux openbsd solaris
+
+package osfs
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// FileTimes returns the access, modification and inode change times of the
+// file described by info, which must come from the os package. ok is false
+// if info carries no such times.
+func FileTimes(info os.FileInfo) (atime, mtime, ctime time.Time, ok bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, time.Time{}, time.Time{}, false
+	}
+	return time.Unix(st.Atim.Unix()), info.ModTime(), time.Unix(st.Ctim.Unix()), true
+}
@@ -0,0 +1,29 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris && !windows
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris,!windows
+
+package osfs
+
+import (
+	"os"
+	"time"
+)
+
+// FileOwner reports ok as false, as ownership is not available on this
+// platform.
+func FileOwner(info os.FileInfo) (uid, gid int, ok bool) {
+	return 0, 0, false
+}
+
+// FileTimes reports ok as false, as file times other than the modification
+// time are not available on this platform.
+func FileTimes(info os.FileInfo) (atime, mtime, ctime time.Time, ok bool) {
+	return time.Time{}, time.Time{}, time.Time{}, false
+}
+
+func fileDevice(info os.FileInfo) (dev uint64, ok bool) {
+	return 0, false
+}
+
+func fileBlockSize(info os.FileInfo) (int, bool) {
+	return 0, false
+}
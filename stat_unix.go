@@ -0,0 +1,38 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package osfs
+
+import (
+	"os"
+	"syscall"
+)
+
+// FileOwner returns the user and group ids of the file described by info,
+// which must come from the os package, such as from os.Stat or a directory
+// listing. ok is false if info carries no ownership.
+func FileOwner(info os.FileInfo) (uid, gid int, ok bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return int(st.Uid), int(st.Gid), true
+}
+
+func fileDevice(info os.FileInfo) (dev uint64, ok bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(st.Dev), true
+}
+
+// fileBlockSize returns the preferred I/O size of the file described by
+// info.
+func fileBlockSize(info os.FileInfo) (int, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || st.Blksize <= 0 {
+		return 0, false
+	}
+	return int(st.Blksize), true
+}
@@ -0,0 +1,33 @@
+package osfs
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// FileOwner reports ok as false, as Windows files have owner SIDs rather
+// than numeric ids.
+func FileOwner(info os.FileInfo) (uid, gid int, ok bool) {
+	return 0, 0, false
+}
+
+// FileTimes returns the access, modification and creation times of the
+// file described by info, which must come from the os package. The
+// creation time is reported as ctime, as Windows does not record an inode
+// change time. ok is false if info carries no such times.
+func FileTimes(info os.FileInfo) (atime, mtime, ctime time.Time, ok bool) {
+	d, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return time.Time{}, time.Time{}, time.Time{}, false
+	}
+	return time.Unix(0, d.LastAccessTime.Nanoseconds()), info.ModTime(), time.Unix(0, d.CreationTime.Nanoseconds()), true
+}
+
+func fileDevice(info os.FileInfo) (dev uint64, ok bool) {
+	return 0, false
+}
+
+func fileBlockSize(info os.FileInfo) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,30 @@
+package osfs
+
+// Usage reports the size and free space of a filesystem, in bytes, and the
+// number of file slots (inodes) it has.
+type Usage struct {
+	// Total is the size of the filesystem.
+	Total uint64
+
+	// Free is the free space, including any reserved for the superuser.
+	Free uint64
+
+	// Available is the free space usable by the calling process.
+	Available uint64
+
+	// FilesTotal and FilesFree are the total and free number of file
+	// slots. They are zero on filesystems without a fixed limit, and on
+	// Windows.
+	FilesTotal uint64
+	FilesFree  uint64
+}
+
+// Statfs returns the usage of the filesystem holding the named file, which
+// must exist.
+func (fs *FileSystem) Statfs(name string) (Usage, error) {
+	path, err := fs.fixPathErr("statfs", name)
+	if err != nil {
+		return Usage{}, err
+	}
+	return statfs(path)
+}
@@ -0,0 +1,34 @@
+//go:build darwin || dragonfly || freebsd
+// +build darwin dragonfly freebsd
+
+package osfs
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+func statfs(path string) (Usage, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return Usage{}, &os.PathError{Op: "statfs", Path: path, Err: err}
+	}
+	bsize := uint64(st.Bsize)
+	// Bavail goes negative on FreeBSD once the reserve is in use.
+	avail := int64(st.Bavail)
+	if avail < 0 {
+		avail = 0
+	}
+	ffree := int64(st.Ffree)
+	if ffree < 0 {
+		ffree = 0
+	}
+	return Usage{
+		Total:      uint64(st.Blocks) * bsize,
+		Free:       uint64(st.Bfree) * bsize,
+		Available:  uint64(avail) * bsize,
+		FilesTotal: uint64(st.Files),
+		FilesFree:  uint64(ffree),
+	}, nil
+}
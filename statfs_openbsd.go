@@ -0,0 +1,26 @@
+package osfs
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+func statfs(path string) (Usage, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return Usage{}, &os.PathError{Op: "statfs", Path: path, Err: err}
+	}
+	bsize := uint64(st.F_bsize)
+	avail := int64(st.F_bavail)
+	if avail < 0 {
+		avail = 0
+	}
+	return Usage{
+		Total:      uint64(st.F_blocks) * bsize,
+		Free:       uint64(st.F_bfree) * bsize,
+		Available:  uint64(avail) * bsize,
+		FilesTotal: uint64(st.F_files),
+		FilesFree:  uint64(st.F_ffree),
+	}, nil
+}
@@ -0,0 +1,10 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris && !windows
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris,!windows
+
+package osfs
+
+import "os"
+
+func statfs(path string) (Usage, error) {
+	return Usage{}, &os.PathError{Op: "statfs", Path: path, Err: ErrNotSupported}
+}
@@ -0,0 +1,29 @@
+//go:build netbsd || solaris
+// +build netbsd solaris
+
+package osfs
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+func statfs(path string) (Usage, error) {
+	var st unix.Statvfs_t
+	if err := unix.Statvfs(path, &st); err != nil {
+		return Usage{}, &os.PathError{Op: "statvfs", Path: path, Err: err}
+	}
+	// Field widths differ between platforms and architectures.
+	bsize := uint64(st.Frsize)
+	if bsize == 0 {
+		bsize = uint64(st.Bsize)
+	}
+	return Usage{
+		Total:      uint64(st.Blocks) * bsize,
+		Free:       uint64(st.Bfree) * bsize,
+		Available:  uint64(st.Bavail) * bsize,
+		FilesTotal: uint64(st.Files),
+		FilesFree:  uint64(st.Ffree),
+	}, nil
+}
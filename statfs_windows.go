@@ -0,0 +1,29 @@
+package osfs
+
+import (
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/windows"
+)
+
+func statfs(path string) (Usage, error) {
+	// GetDiskFreeSpaceEx wants a directory that exists.
+	info, err := os.Stat(path)
+	if err != nil {
+		return Usage{}, err
+	}
+	dir := path
+	if !info.IsDir() {
+		dir = filepath.Dir(path)
+	}
+	p, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return Usage{}, &os.PathError{Op: "statfs", Path: path, Err: err}
+	}
+	var u Usage
+	if err := windows.GetDiskFreeSpaceEx(p, &u.Available, &u.Total, &u.Free); err != nil {
+		return Usage{}, &os.PathError{Op: "getdiskfreespaceex", Path: path, Err: err}
+	}
+	return u, nil
+}
@@ -0,0 +1,79 @@
+package osfs
+
+import (
+	"os"
+	"runtime"
+	"sync"
+)
+
+// statManyBatch is the number of paths StatMany hands to a worker at a
+// time. Smaller batches are stat'd on the calling goroutine.
+const statManyBatch = 64
+
+// StatResult is the outcome of stat'ing one path in StatMany.
+type StatResult struct {
+	Name string
+	Info os.FileInfo
+	Err  error
+}
+
+// StatMany stats every name, following symbolic links, and returns the
+// results in the order of names. Large batches are spread over up to
+// GOMAXPROCS goroutines, which pays off when the metadata is not cached,
+// as on network filesystems. The returned error is a MultiError of the
+// per-path errors, or nil if every Stat succeeded.
+func (fs *FileSystem) StatMany(names []string) ([]StatResult, error) {
+	results := make([]StatResult, len(names))
+	stat := func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			results[i].Name = names[i]
+			results[i].Info, results[i].Err = fs.Stat(names[i])
+		}
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	if n := (len(names) + statManyBatch - 1) / statManyBatch; n < workers {
+		workers = n
+	}
+	if workers <= 1 {
+		stat(0, len(names))
+	} else {
+		var (
+			wg   sync.WaitGroup
+			mu   sync.Mutex
+			next int
+		)
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					mu.Lock()
+					lo := next
+					next += statManyBatch
+					mu.Unlock()
+					if lo >= len(names) {
+						return
+					}
+					hi := lo + statManyBatch
+					if hi > len(names) {
+						hi = len(names)
+					}
+					stat(lo, hi)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	var errs MultiError
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+		}
+	}
+	if len(errs) > 0 {
+		return results, errs
+	}
+	return results, nil
+}
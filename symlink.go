@@ -0,0 +1,18 @@
+package osfs
+
+// SymlinkKind selects the kind of link FileSystem.Symlink creates on Windows.
+type SymlinkKind int
+
+const (
+	// SymlinkAuto creates a directory symbolic link if the target is an
+	// existing directory and a file symbolic link otherwise. When the
+	// process lacks the privilege to create symbolic links, a directory
+	// target is linked with a junction instead, which needs no privilege.
+	SymlinkAuto SymlinkKind = iota
+
+	// SymlinkFile always creates a file symbolic link.
+	SymlinkFile
+
+	// SymlinkDir always creates a directory symbolic link.
+	SymlinkDir
+)
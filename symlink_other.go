@@ -0,0 +1,10 @@
+//go:build !windows
+// +build !windows
+
+package osfs
+
+import "os"
+
+func (fs *FileSystem) symlink(oldname, newname string) error {
+	return os.Symlink(oldname, newname)
+}
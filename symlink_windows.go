@@ -0,0 +1,120 @@
+package osfs
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"unicode/utf16"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	symbolicLinkFlagAllowUnprivilegedCreate = 0x2
+	fsctlSetReparsePoint                    = 0x000900A4
+)
+
+func (fs *FileSystem) symlink(oldname, newname string) error {
+	target := oldname
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(newname), target)
+	}
+
+	kind := fs.SymlinkKind
+	if kind == SymlinkAuto {
+		kind = SymlinkFile
+		if info, err := os.Stat(target); err == nil && info.IsDir() {
+			kind = SymlinkDir
+		}
+	}
+	var flags uint32 = symbolicLinkFlagAllowUnprivilegedCreate
+	if kind == SymlinkDir {
+		flags |= windows.SYMBOLIC_LINK_FLAG_DIRECTORY
+	}
+
+	err := createSymbolicLink(newname, oldname, flags)
+	if err == windows.ERROR_INVALID_PARAMETER {
+		// Windows before 10 1703 rejects the unprivileged flag.
+		err = createSymbolicLink(newname, oldname, flags&^symbolicLinkFlagAllowUnprivilegedCreate)
+	}
+	if err == windows.ERROR_PRIVILEGE_NOT_HELD && fs.SymlinkKind == SymlinkAuto && kind == SymlinkDir {
+		err = createJunction(newname, target)
+	}
+	if err == windows.ERROR_PRIVILEGE_NOT_HELD {
+		err = fmt.Errorf("%w: creating symbolic links requires SeCreateSymbolicLinkPrivilege, usually granted by running elevated or enabling Developer Mode", err)
+	}
+	if err != nil {
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: err}
+	}
+	return nil
+}
+
+func createSymbolicLink(link, target string, flags uint32) error {
+	l, err := windows.UTF16PtrFromString(link)
+	if err != nil {
+		return err
+	}
+	t, err := windows.UTF16PtrFromString(filepath.FromSlash(target))
+	if err != nil {
+		return err
+	}
+	return windows.CreateSymbolicLink(l, t, flags)
+}
+
+// createJunction creates link as an NTFS junction to the directory target.
+// Junctions need no privilege but only point at absolute local paths.
+func createJunction(link, target string) error {
+	target, err := filepath.Abs(target)
+	if err != nil {
+		return err
+	}
+	if err := os.Mkdir(link, 0777); err != nil {
+		return err
+	}
+	if err := setMountPoint(link, target); err != nil {
+		os.Remove(link)
+		return err
+	}
+	return nil
+}
+
+// setMountPoint attaches a mount point reparse point for target to the empty
+// directory dir.
+func setMountPoint(dir, target string) error {
+	p, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return err
+	}
+	h, err := windows.CreateFile(p, windows.GENERIC_WRITE, 0, nil, windows.OPEN_EXISTING,
+		windows.FILE_FLAG_OPEN_REPARSE_POINT|windows.FILE_FLAG_BACKUP_SEMANTICS, 0)
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(h)
+
+	subst := utf16.Encode([]rune(`\??\` + target))
+	print := utf16.Encode([]rune(target))
+	names := append(append(append(subst, 0), print...), 0)
+	if 8+2*len(names) > 0xffff {
+		return errors.New("junction target too long")
+	}
+
+	// REPARSE_DATA_BUFFER: tag, data length and a reserved word, followed
+	// by the offsets and lengths of the two names and the names themselves.
+	le := binary.LittleEndian
+	buf := make([]byte, 16+2*len(names))
+	le.PutUint32(buf[0:], windows.IO_REPARSE_TAG_MOUNT_POINT)
+	le.PutUint16(buf[4:], uint16(8+2*len(names)))
+	le.PutUint16(buf[8:], 0)
+	le.PutUint16(buf[10:], uint16(2*len(subst)))
+	le.PutUint16(buf[12:], uint16(2*(len(subst)+1)))
+	le.PutUint16(buf[14:], uint16(2*len(print)))
+	for i, c := range names {
+		le.PutUint16(buf[16+2*i:], c)
+	}
+
+	var n uint32
+	return windows.DeviceIoControl(h, fsctlSetReparsePoint, &buf[0], uint32(len(buf)), nil, 0, &n, nil)
+}
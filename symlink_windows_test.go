@@ -0,0 +1,35 @@
+package osfs_test
+
+import (
+	"testing"
+
+	"github.com/absfs/osfs"
+)
+
+func TestSymlinkKind(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	if err := fs.Mkdir("dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFile("dir/file", []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// SymlinkAuto links a directory with a directory symlink or, without
+	// the privilege for one, a junction. Either can be traversed.
+	if err := fs.Symlink("dir", "dirlink"); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := fs.ReadFileInto("dirlink/file", nil); err != nil || string(data) != "data" {
+		t.Errorf("reading through the directory link: %q, %v", data, err)
+	}
+
+	fs.SymlinkKind = osfs.SymlinkFile
+	if err := fs.Symlink("dir/file", "filelink"); err != nil {
+		t.Skipf("symlinks not available: %v", err)
+	}
+	if data, err := fs.ReadFileInto("filelink", nil); err != nil || string(data) != "data" {
+		t.Errorf("reading through the file link: %q, %v", data, err)
+	}
+}
@@ -0,0 +1,128 @@
+package osfs
+
+import (
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/absfs/absfs"
+)
+
+// DefaultTempPattern is the temporary file pattern used when
+// FileSystem.TempPattern is empty.
+const DefaultTempPattern = ".tmp-*"
+
+// tempRegistry tracks the native paths of temporary files created by a
+// FileSystem until they are renamed into place or removed.
+type tempRegistry struct {
+	mu    sync.Mutex
+	files map[string]struct{}
+}
+
+func (r *tempRegistry) add(path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.files == nil {
+		r.files = make(map[string]struct{})
+	}
+	r.files[path] = struct{}{}
+}
+
+func (r *tempRegistry) remove(path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.files, path)
+}
+
+func (r *tempRegistry) list() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	paths := make([]string, 0, len(r.files))
+	for path := range r.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// createTemp creates a registered temporary file in the native directory
+// dir. An empty pattern selects the FileSystem's TempPattern.
+func (fs *FileSystem) createTemp(dir, pattern string) (*os.File, error) {
+	if pattern == "" {
+		pattern = fs.TempPattern
+	}
+	if pattern == "" {
+		pattern = DefaultTempPattern
+	}
+	f, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return nil, err
+	}
+	fs.temps.add(f.Name())
+	return f, nil
+}
+
+// CreateTemp creates a new temporary file in the directory dir, opened for
+// reading and writing, and returns it. The file name is generated from
+// pattern as with os.CreateTemp; an empty pattern selects TempPattern. An
+// empty dir selects TempDir.
+//
+// The file is tracked until it is renamed or removed through the
+// FileSystem, and CleanupTemp deletes it if neither happens. Callers that
+// want to keep a temporary file under its generated name should not call
+// CleanupTemp.
+func (fs *FileSystem) CreateTemp(dir, pattern string) (absfs.File, error) {
+	tmpdir, err := fs.tempDir("createtemp", dir)
+	if err != nil {
+		return nil, err
+	}
+	f, err := fs.createTemp(tmpdir, pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &File{fs, f}, nil
+}
+
+// MkdirTemp creates a new temporary directory in the directory dir and
+// returns its absfs path. The name is generated from pattern as with
+// os.MkdirTemp. An empty dir selects TempDir. Temporary directories are not
+// tracked by CleanupTemp.
+func (fs *FileSystem) MkdirTemp(dir, pattern string) (string, error) {
+	tmpdir, err := fs.tempDir("mkdirtemp", dir)
+	if err != nil {
+		return "", err
+	}
+	name, err := os.MkdirTemp(tmpdir, pattern)
+	if err != nil {
+		return "", err
+	}
+	return FromNative(name), nil
+}
+
+// tempDir returns the native directory for a temporary file or directory
+// made by op in dir.
+func (fs *FileSystem) tempDir(op, dir string) (string, error) {
+	if dir == "" {
+		return fs.TempDir(), nil
+	}
+	return fs.fixPathErr(op, dir)
+}
+
+// CleanupTemp removes every temporary file created by the FileSystem that
+// has not since been renamed into place or removed, such as those left
+// behind by an aborted atomic write. Files that no longer exist are ignored.
+// CleanupTemp attempts every file and returns the first error encountered.
+func (fs *FileSystem) CleanupTemp() error {
+	var first error
+	for _, path := range fs.temps.list() {
+		err := os.Remove(path)
+		if err == nil || os.IsNotExist(err) {
+			fs.temps.remove(path)
+			continue
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return first
+}
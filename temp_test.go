@@ -0,0 +1,122 @@
+package osfs_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/absfs/osfs"
+)
+
+func TestCreateTemp(t *testing.T) {
+	fs, dir := newTempFS(t)
+
+	f, err := fs.CreateTemp(".", "temp-*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	name := filepath.Base(f.Name())
+	if filepath.Dir(f.Name()) != dir || !strings.HasPrefix(name, "temp-") || !strings.HasSuffix(name, ".txt") {
+		t.Errorf("unexpected temp file name %q", f.Name())
+	}
+	if _, err := f.WriteString("data"); err != nil {
+		t.Fatal(err)
+	}
+
+	kept, err := fs.CreateTemp("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kept.Close()
+	if filepath.Dir(kept.Name()) != filepath.Clean(fs.TempDir()) {
+		t.Errorf("temp file %q not created in %q", kept.Name(), fs.TempDir())
+	}
+	if !strings.HasPrefix(filepath.Base(kept.Name()), ".tmp-") {
+		t.Errorf("temp file %q does not use the default pattern", kept.Name())
+	}
+	if err := fs.Rename(kept.Name(), "kept.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fs.CleanupTemp(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.Stat(f.Name()); !os.IsNotExist(err) {
+		t.Errorf("unfinalized temp file was not removed: %v", err)
+	}
+	if _, err := fs.Stat("kept.txt"); err != nil {
+		t.Errorf("renamed temp file was removed: %v", err)
+	}
+}
+
+func TestMkdirTemp(t *testing.T) {
+	fs, dir := newTempFS(t)
+
+	name, err := fs.MkdirTemp("", "dir-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.RemoveAll(name)
+
+	name, err = fs.MkdirTemp(".", "dir-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(name, osfs.FromNative(dir)+"/dir-") {
+		t.Errorf("MkdirTemp = %q, want an absfs path under %q", name, dir)
+	}
+	info, err := fs.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.IsDir() {
+		t.Errorf("%q is not a directory", name)
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	for _, data := range []string{"first", "second version"} {
+		if err := fs.WriteFileAtomic("config", []byte(data), 0640); err != nil {
+			t.Fatal(err)
+		}
+		got, err := fs.ReadFileInto("config", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != data {
+			t.Errorf("config holds %q, want %q", got, data)
+		}
+	}
+	if runtime.GOOS != "windows" {
+		info, err := fs.Stat("config")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0640 {
+			t.Errorf("config has mode %v, want %v", info.Mode().Perm(), os.FileMode(0640))
+		}
+	}
+
+	if err := fs.Mkdir("dir", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.WriteFileAtomic("dir", []byte("x"), 0644); err == nil {
+		t.Error("replacing a directory succeeded")
+	}
+	if err := fs.WriteFileAtomic("missing/file", []byte("x"), 0644); err == nil {
+		t.Error("writing into a missing directory succeeded")
+	}
+
+	names, err := fs.Glob("*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 {
+		t.Errorf("temporary files left behind: %q", names)
+	}
+}
@@ -0,0 +1,5 @@
+package osfs
+
+// utimeOmit is the nanoseconds value that makes utimensat leave a time
+// unchanged. golang.org/x/sys/unix does not define it for netbsd.
+const utimeOmit = 1<<30 - 2
@@ -0,0 +1,10 @@
+//go:build aix || dragonfly || freebsd || linux || openbsd || solaris
+// +build aix dragonfly freebsd linux openbsd solaris
+
+package osfs
+
+import "golang.org/x/sys/unix"
+
+// utimeOmit is the nanoseconds value that makes utimensat leave a time
+// unchanged.
+const utimeOmit = unix.UTIME_OMIT
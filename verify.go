@@ -0,0 +1,88 @@
+package osfs
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// VerifyFailure describes one entry VerifyReadable could not open.
+type VerifyFailure struct {
+	Path string
+
+	// Permission is true if the entry could not be opened for lack of
+	// permission, and false for I/O and other errors.
+	Permission bool
+
+	Err error
+}
+
+// VerifyReadable walks the tree rooted at root and opens, then immediately
+// closes, every regular file in it, following symbolic links to files. It
+// returns the files, and the directories, that could not be opened, in walk
+// order. No data is read, so the pass is cheap even for large trees.
+//
+// If anything is unreadable the error is a MultiError of the failures'
+// errors, each an *os.PathError, in the same order. An error that prevents
+// the walk itself, such as a missing root, is returned as is with no
+// failures.
+func (fs *FileSystem) VerifyReadable(root string) ([]VerifyFailure, error) {
+	root, err := fs.fixPathErr("open", root)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(root); err != nil {
+		return nil, err
+	}
+
+	var failures []VerifyFailure
+	var errs MultiError
+	fail := func(path string, err error) {
+		if _, ok := err.(*os.PathError); !ok {
+			err = &os.PathError{Op: "open", Path: path, Err: err}
+		}
+		failures = append(failures, VerifyFailure{
+			Path:       FromNative(path),
+			Permission: os.IsPermission(err),
+			Err:        err,
+		})
+		errs = append(errs, err)
+	}
+
+	err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			fail(path, err)
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch {
+		case d.Type().IsRegular():
+		case d.Type()&os.ModeSymlink != 0:
+			info, err := os.Stat(path)
+			if err != nil {
+				fail(path, err)
+				return nil
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+		default:
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			fail(path, err)
+			return nil
+		}
+		return f.Close()
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return failures, errs
+	}
+	return nil, nil
+}
@@ -0,0 +1,103 @@
+package osfs
+
+import (
+	iofs "io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// WalkOptions configures WalkWithOptions.
+type WalkOptions struct {
+	// OneFileSystem keeps the walk on the filesystem of root. Directories
+	// on another device, that is mount points, are still passed to fn but
+	// not descended into.
+	OneFileSystem bool
+}
+
+// WalkDir walks the file tree rooted at root like fs.WalkDir, calling fn for
+// each file or directory in the tree, including root, in lexical order. The
+// paths passed to fn are absfs paths starting with root, so they use `/` on
+// every platform. Entry types come from the directory listing, so no file
+// is stat'd unless fn calls Info. Returning fs.SkipDir from fn skips a
+// directory, or the rest of the current directory after a file, and, on Go
+// versions that define it, fs.SkipAll ends the walk without error.
+// Symbolic links are not followed.
+func (fs *FileSystem) WalkDir(root string, fn iofs.WalkDirFunc) error {
+	return fs.WalkWithOptions(root, WalkOptions{}, fn)
+}
+
+// WalkWithOptions is WalkDir configured by opts.
+//
+// With opts.OneFileSystem every directory below root is stat'd to compare
+// its device with that of root; on Windows, where FileInfo carries no
+// device, each directory is checked with IsMountPoint instead. If that
+// check fails, fn is called for the directory with the error, and the
+// directory is not descended into unless fn returns nil.
+func (fs *FileSystem) WalkWithOptions(root string, opts WalkOptions, fn iofs.WalkDirFunc) error {
+	return fs.walk(root, opts, func(name, _ string, d iofs.DirEntry, err error) error {
+		return fn(name, d, err)
+	})
+}
+
+// NativeWalkFunc is the type of the function called by WalkNative for each
+// file or directory. It receives the path both as an absfs path, using `/`,
+// and as the native path passed to the operating system.
+type NativeWalkFunc func(unixPath, nativePath string, d iofs.DirEntry, err error) error
+
+// WalkNative is WalkDir for callers that also need the native path of each
+// entry, for example to pass it to a subprocess or a C library. The native
+// path comes from the walk itself, so no conversion with ToNative is
+// needed.
+func (fs *FileSystem) WalkNative(root string, fn NativeWalkFunc) error {
+	return fs.walk(root, WalkOptions{}, fn)
+}
+
+func (fs *FileSystem) walk(root string, opts WalkOptions, fn NativeWalkFunc) error {
+	native, err := fs.fixPathErr("lstat", root)
+	if err != nil {
+		return fn(root, "", nil, err)
+	}
+
+	var rootDev uint64
+	haveDev := false
+	if opts.OneFileSystem {
+		if info, err := os.Lstat(native); err == nil {
+			rootDev, haveDev = fileDevice(info)
+		}
+	}
+
+	return filepath.WalkDir(native, func(p string, d iofs.DirEntry, err error) error {
+		name := root
+		if p != native {
+			name = path.Join(root, FromNative(p[len(native):]))
+		}
+		if err != nil || !opts.OneFileSystem || p == native || !d.IsDir() {
+			return fn(name, p, d, err)
+		}
+
+		other, err := crossesDevice(p, d, rootDev, haveDev)
+		if err != nil {
+			return fn(name, p, d, err)
+		}
+		if err := fn(name, p, d, nil); err != nil || !other {
+			return err
+		}
+		return filepath.SkipDir
+	})
+}
+
+// crossesDevice reports whether the directory p, described by d, lies on a
+// device other than the walk root's, rootDev if haveDev is set.
+func crossesDevice(p string, d iofs.DirEntry, rootDev uint64, haveDev bool) (bool, error) {
+	if haveDev {
+		info, err := d.Info()
+		if err != nil {
+			return false, err
+		}
+		if dev, ok := fileDevice(info); ok {
+			return dev != rootDev, nil
+		}
+	}
+	return isMountPoint(p)
+}
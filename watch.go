@@ -0,0 +1,186 @@
+package osfs
+
+import (
+	"errors"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+// WatchOp is a set of changes reported by a Watcher.
+type WatchOp uint32
+
+const (
+	// WatchCreate reports a new file or directory, including one renamed
+	// into a watched directory.
+	WatchCreate WatchOp = 1 << iota
+	// WatchWrite reports a change to the contents of a file.
+	WatchWrite
+	// WatchRemove reports a removed file or directory.
+	WatchRemove
+	// WatchRename reports a file or directory renamed away from its path.
+	WatchRename
+	// WatchChmod reports a change to permissions or other attributes.
+	WatchChmod
+)
+
+var watchOpNames = []string{"CREATE", "WRITE", "REMOVE", "RENAME", "CHMOD"}
+
+// String returns the names of the ops in op joined with "|", for example
+// "CREATE|WRITE".
+func (op WatchOp) String() string {
+	var names []string
+	for i, name := range watchOpNames {
+		if op&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "0"
+	}
+	return strings.Join(names, "|")
+}
+
+// Event is a change reported by a Watcher.
+type Event struct {
+	// Name is the absfs path of the changed file. It starts with the name
+	// passed to Watch and uses `/` on every platform.
+	Name string
+	// Op is the change.
+	Op WatchOp
+}
+
+// String returns a description of e such as `CREATE "/tmp/dir/file"`.
+func (e Event) String() string {
+	return e.Op.String() + " " + `"` + e.Name + `"`
+}
+
+// errWatchOverflow is sent on the error channel of a Watcher when the
+// operating system dropped events.
+var errWatchOverflow = errors.New("osfs: watch event queue overflowed")
+
+// errWatchClosed stops adding watches once the watcher is closed.
+var errWatchClosed = errors.New("osfs: watcher closed")
+
+// WatchOptions configures WatchWithOptions.
+type WatchOptions struct {
+	// Recursive also watches every directory below a watched directory,
+	// including directories created while watching.
+	Recursive bool
+}
+
+// Watcher delivers changes to a watched file or directory tree. Events and
+// errors must be received promptly, as the watcher blocks until they are.
+type Watcher struct {
+	root   string // absfs name passed to Watch
+	native string // native path of root
+
+	events chan Event
+	errors chan error
+	done   chan struct{} // closed by Close
+	exited chan struct{} // closed when the reading goroutine returns
+
+	once sync.Once
+	err  error
+	sys  *watcher
+}
+
+// Watch watches name for changes. For a directory, changes to the directory
+// itself and to its entries are reported; for a file, changes to the file.
+// It is WatchWithOptions with no options.
+func (fs *FileSystem) Watch(name string) (*Watcher, error) {
+	return fs.WatchWithOptions(name, WatchOptions{})
+}
+
+// WatchWithOptions is Watch configured by opts.
+//
+// Watching uses inotify on Linux, kqueue on macOS and the BSDs, which
+// needs an open descriptor for every watched file and directory, and
+// ReadDirectoryChangesW on Windows, where attribute changes are reported as
+// WatchWrite. On other platforms it returns an error wrapping
+// ErrNotSupported. Which events a change produces, and in what order,
+// differs between these, so callers should treat events as hints and stat
+// the file when its state matters.
+func (fs *FileSystem) WatchWithOptions(name string, opts WatchOptions) (*Watcher, error) {
+	native, err := fs.fixPathErr("watch", name)
+	if err != nil {
+		return nil, err
+	}
+	w := &Watcher{
+		root:   name,
+		native: native,
+		events: make(chan Event),
+		errors: make(chan error),
+		done:   make(chan struct{}),
+		exited: make(chan struct{}),
+	}
+	sys, err := startWatch(w, opts.Recursive)
+	if err != nil {
+		return nil, &os.PathError{Op: "watch", Path: name, Err: err}
+	}
+	w.sys = sys
+	return w, nil
+}
+
+// Events returns the channel on which changes are delivered. It is closed
+// by Close.
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// Errors returns the channel on which errors met while watching are
+// delivered, such as an overflow of the operating system's event queue. It
+// is closed by Close.
+func (w *Watcher) Errors() <-chan error {
+	return w.errors
+}
+
+// Close stops watching, releases the watcher's resources and closes the
+// channels returned by Events and Errors. Calling it more than once is
+// harmless.
+func (w *Watcher) Close() error {
+	w.once.Do(func() {
+		close(w.done)
+		w.err = w.sys.close()
+		<-w.exited
+		close(w.events)
+		close(w.errors)
+	})
+	return w.err
+}
+
+// send delivers a change to the native path p, reporting false once the
+// watcher is closed.
+func (w *Watcher) send(p string, op WatchOp) bool {
+	name := w.root
+	if p != w.native {
+		name = path.Join(w.root, FromNative(p[len(w.native):]))
+	}
+	select {
+	case w.events <- Event{Name: name, Op: op}:
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+// fail delivers err, reporting false once the watcher is closed.
+func (w *Watcher) fail(err error) bool {
+	select {
+	case w.errors <- err:
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+// closed reports whether Close has been called.
+func (w *Watcher) closed() bool {
+	select {
+	case <-w.done:
+		return true
+	default:
+		return false
+	}
+}
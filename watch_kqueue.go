@@ -0,0 +1,256 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+// +build darwin dragonfly freebsd netbsd openbsd
+
+package osfs
+
+import (
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+const kqueueNotes = unix.NOTE_DELETE | unix.NOTE_WRITE | unix.NOTE_EXTEND |
+	unix.NOTE_ATTRIB | unix.NOTE_RENAME
+
+// watcher is a kqueue with a vnode filter on every watched file and
+// directory. kqueue does not name the entries that changed in a directory,
+// so each directory's listing is kept and compared after it is written.
+type watcher struct {
+	kq        int
+	wake      [2]int // pipe written by close to wake the reading goroutine
+	recursive bool
+	files     map[int]*kqueueWatch // descriptor to watch
+	paths     map[string]int       // native path to descriptor
+}
+
+type kqueueWatch struct {
+	path    string
+	dir     bool
+	entries map[string]bool // names in a watched directory
+}
+
+func startWatch(w *Watcher, recursive bool) (*watcher, error) {
+	kq, err := unix.Kqueue()
+	if err != nil {
+		return nil, os.NewSyscallError("kqueue", err)
+	}
+	unix.CloseOnExec(kq)
+	sys := &watcher{
+		kq:        kq,
+		recursive: recursive,
+		files:     make(map[int]*kqueueWatch),
+		paths:     make(map[string]int),
+	}
+	if err := unix.Pipe(sys.wake[:]); err != nil {
+		unix.Close(kq)
+		return nil, os.NewSyscallError("pipe", err)
+	}
+	unix.CloseOnExec(sys.wake[0])
+	unix.CloseOnExec(sys.wake[1])
+
+	ev := make([]unix.Kevent_t, 1)
+	unix.SetKevent(&ev[0], sys.wake[0], unix.EVFILT_READ, unix.EV_ADD)
+	if _, err = unix.Kevent(kq, ev, nil, nil); err != nil {
+		err = os.NewSyscallError("kevent", err)
+	} else {
+		err = sys.add(w.native, true, nil)
+	}
+	if err != nil {
+		sys.release()
+		return nil, err
+	}
+	go sys.run(w)
+	return sys, nil
+}
+
+// close wakes the reading goroutine, which releases the descriptors once it
+// stops.
+func (sys *watcher) close() error {
+	_, err := unix.Write(sys.wake[1], []byte{0})
+	return err
+}
+
+func (sys *watcher) release() {
+	for fd := range sys.files {
+		unix.Close(fd)
+	}
+	unix.Close(sys.kq)
+	unix.Close(sys.wake[0])
+	unix.Close(sys.wake[1])
+}
+
+// add watches p. Below the root, directories are only watched when
+// recursive, and symbolic links are not followed. In a watched directory
+// every entry is watched too, and found, if not nil, is called with the
+// path of each; add stops when it returns false.
+func (sys *watcher) add(p string, root bool, found func(string) bool) error {
+	if _, ok := sys.paths[p]; ok {
+		return nil
+	}
+	var st unix.Stat_t
+	if err := unix.Lstat(p, &st); err != nil {
+		return err
+	}
+	dir := st.Mode&unix.S_IFMT == unix.S_IFDIR
+	if !root && (st.Mode&unix.S_IFMT == unix.S_IFLNK || dir && !sys.recursive) {
+		return nil
+	}
+
+	fd, err := unix.Open(p, unix.O_RDONLY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return err
+	}
+	ev := make([]unix.Kevent_t, 1)
+	unix.SetKevent(&ev[0], fd, unix.EVFILT_VNODE, unix.EV_ADD|unix.EV_CLEAR|unix.EV_ENABLE)
+	ev[0].Fflags = kqueueNotes
+	if _, err := unix.Kevent(sys.kq, ev, nil, nil); err != nil {
+		unix.Close(fd)
+		return os.NewSyscallError("kevent", err)
+	}
+	kw := &kqueueWatch{path: p, dir: dir}
+	sys.files[fd] = kw
+	sys.paths[p] = fd
+	if !dir {
+		return nil
+	}
+
+	names, err := readDirNames(p)
+	if err != nil {
+		return err
+	}
+	kw.entries = make(map[string]bool, len(names))
+	for _, name := range names {
+		kw.entries[name] = true
+		q := filepath.Join(p, name)
+		if found != nil && !found(q) {
+			return errWatchClosed
+		}
+		if err := sys.add(q, false, found); err != nil && err != unix.ENOENT {
+			return err
+		}
+	}
+	return nil
+}
+
+// remove stops watching p and forgets it in its parent's listing, so that
+// it is not reported again.
+func (sys *watcher) remove(p string) {
+	if fd, ok := sys.paths[p]; ok {
+		unix.Close(fd)
+		delete(sys.files, fd)
+		delete(sys.paths, p)
+	}
+	if fd, ok := sys.paths[filepath.Dir(p)]; ok {
+		delete(sys.files[fd].entries, filepath.Base(p))
+	}
+}
+
+func (sys *watcher) run(w *Watcher) {
+	defer close(w.exited)
+	defer sys.release()
+	events := make([]unix.Kevent_t, 32)
+	for {
+		n, err := unix.Kevent(sys.kq, nil, events, nil)
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			if !w.closed() {
+				w.fail(os.NewSyscallError("kevent", err))
+			}
+			return
+		}
+		for _, ev := range events[:n] {
+			fd := int(ev.Ident)
+			if fd == sys.wake[0] {
+				return
+			}
+			kw, ok := sys.files[fd]
+			if ok && !sys.handle(w, kw, uint32(ev.Fflags)) {
+				return
+			}
+		}
+	}
+}
+
+// handle reports the changes in notes to the watched kw, returning false
+// once the watcher is closed.
+func (sys *watcher) handle(w *Watcher, kw *kqueueWatch, notes uint32) bool {
+	switch {
+	case notes&unix.NOTE_DELETE != 0:
+		sys.remove(kw.path)
+		return w.send(kw.path, WatchRemove)
+	case notes&unix.NOTE_RENAME != 0:
+		sys.remove(kw.path)
+		return w.send(kw.path, WatchRename)
+	}
+	if notes&unix.NOTE_WRITE != 0 && kw.dir {
+		if !sys.rescan(w, kw) {
+			return false
+		}
+	} else if notes&(unix.NOTE_WRITE|unix.NOTE_EXTEND) != 0 {
+		if !w.send(kw.path, WatchWrite) {
+			return false
+		}
+	}
+	if notes&unix.NOTE_ATTRIB != 0 {
+		return w.send(kw.path, WatchChmod)
+	}
+	return true
+}
+
+// rescan compares the listing of the directory kw with the one kept, and
+// reports the entries created and removed since.
+func (sys *watcher) rescan(w *Watcher, kw *kqueueWatch) bool {
+	names, err := readDirNames(kw.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return true
+		}
+		return w.fail(err)
+	}
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		seen[name] = true
+		if kw.entries[name] {
+			continue
+		}
+		kw.entries[name] = true
+		q := filepath.Join(kw.path, name)
+		if !w.send(q, WatchCreate) {
+			return false
+		}
+		// Entries of a new directory may predate its watch, so they are
+		// reported here; some may be reported twice.
+		err := sys.add(q, false, func(r string) bool {
+			return w.send(r, WatchCreate)
+		})
+		if err == errWatchClosed {
+			return false
+		}
+		if err != nil && err != unix.ENOENT && !w.fail(err) {
+			return false
+		}
+	}
+	for name := range kw.entries {
+		if seen[name] {
+			continue
+		}
+		q := filepath.Join(kw.path, name)
+		sys.remove(q)
+		if !w.send(q, WatchRemove) {
+			return false
+		}
+	}
+	return true
+}
+
+func readDirNames(p string) ([]string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Readdirnames(-1)
+}
@@ -0,0 +1,171 @@
+package osfs
+
+import (
+	"errors"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const inotifyMask = unix.IN_CREATE | unix.IN_MODIFY | unix.IN_ATTRIB |
+	unix.IN_DELETE | unix.IN_DELETE_SELF | unix.IN_MOVED_FROM |
+	unix.IN_MOVED_TO | unix.IN_MOVE_SELF
+
+// watcher is an inotify instance read through the runtime poller, so that
+// closing f wakes a blocked read.
+type watcher struct {
+	f         *os.File
+	fd        int
+	recursive bool
+	paths     map[int]string // watch descriptor to native path
+}
+
+func startWatch(w *Watcher, recursive bool) (*watcher, error) {
+	var st unix.Stat_t
+	if err := unix.Stat(w.native, &st); err != nil {
+		return nil, err
+	}
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+	sys := &watcher{
+		f:         os.NewFile(uintptr(fd), "inotify"),
+		fd:        fd,
+		recursive: recursive,
+		paths:     make(map[int]string),
+	}
+	if recursive && st.Mode&unix.S_IFMT == unix.S_IFDIR {
+		err = sys.addTree(w.native, nil)
+	} else {
+		err = sys.add(w.native)
+	}
+	if err != nil {
+		sys.f.Close()
+		return nil, err
+	}
+	go sys.run(w)
+	return sys, nil
+}
+
+func (sys *watcher) close() error {
+	return sys.f.Close()
+}
+
+func (sys *watcher) add(p string) error {
+	wd, err := unix.InotifyAddWatch(sys.fd, p, inotifyMask)
+	if err != nil {
+		return os.NewSyscallError("inotify_add_watch", err)
+	}
+	sys.paths[wd] = p
+	return nil
+}
+
+// addTree watches the directory p and every directory below it, calling
+// found, if not nil, for each entry below p; the walk stops when found
+// returns false. Entries that vanish while walking are skipped.
+func (sys *watcher) addTree(p string, found func(string) bool) error {
+	return filepath.WalkDir(p, func(q string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && q != p {
+				return nil
+			}
+			return err
+		}
+		if q != p && found != nil && !found(q) {
+			return errWatchClosed
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if err := sys.add(q); err != nil && (q == p || !errors.Is(err, unix.ENOENT)) {
+			return err
+		}
+		return nil
+	})
+}
+
+func (sys *watcher) run(w *Watcher) {
+	defer close(w.exited)
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	for {
+		n, err := sys.f.Read(buf)
+		if err != nil {
+			if !w.closed() {
+				w.fail(err)
+			}
+			return
+		}
+		for off := 0; off+unix.SizeofInotifyEvent <= n; {
+			ev := (*unix.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			start := off + unix.SizeofInotifyEvent
+			off = start + int(ev.Len)
+			name := strings.TrimRight(string(buf[start:off]), "\x00")
+			if !sys.handle(w, int(ev.Wd), ev.Mask, name) {
+				return
+			}
+		}
+	}
+}
+
+// handle reports one inotify event, returning false once the watcher is
+// closed.
+func (sys *watcher) handle(w *Watcher, wd int, mask uint32, name string) bool {
+	if mask&unix.IN_Q_OVERFLOW != 0 {
+		return w.fail(errWatchOverflow)
+	}
+	dir, ok := sys.paths[wd]
+	if !ok {
+		return true
+	}
+	if mask&unix.IN_IGNORED != 0 {
+		delete(sys.paths, wd)
+		return true
+	}
+	p := dir
+	if name != "" {
+		p = filepath.Join(dir, name)
+	}
+
+	// A watched directory below the root also reports its own removal and
+	// renaming, which its parent has already reported for the entry.
+	self := p == w.native
+	var op WatchOp
+	switch {
+	case mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0:
+		op = WatchCreate
+	case mask&unix.IN_MODIFY != 0:
+		op = WatchWrite
+	case mask&unix.IN_ATTRIB != 0:
+		op = WatchChmod
+	case mask&unix.IN_DELETE != 0, mask&unix.IN_DELETE_SELF != 0 && self:
+		op = WatchRemove
+	case mask&unix.IN_MOVED_FROM != 0, mask&unix.IN_MOVE_SELF != 0 && self:
+		op = WatchRename
+	default:
+		return true
+	}
+	if !w.send(p, op) {
+		return false
+	}
+
+	if op != WatchCreate || mask&unix.IN_ISDIR == 0 || !sys.recursive {
+		return true
+	}
+	// Entries created in the new directory before it was watched are
+	// reported here; some may be reported twice.
+	err := sys.addTree(p, func(q string) bool {
+		return w.send(q, WatchCreate)
+	})
+	if err == errWatchClosed {
+		return false
+	}
+	if err != nil && !errors.Is(err, unix.ENOENT) && !os.IsNotExist(err) {
+		return w.fail(err)
+	}
+	return true
+}
@@ -0,0 +1,14 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!windows
+
+package osfs
+
+type watcher struct{}
+
+func startWatch(w *Watcher, recursive bool) (*watcher, error) {
+	return nil, ErrNotSupported
+}
+
+func (sys *watcher) close() error {
+	return nil
+}
@@ -0,0 +1,108 @@
+package osfs_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/absfs/osfs"
+)
+
+// waitEvent receives events from w until one for name with op arrives,
+// failing the test after a timeout.
+func waitEvent(t *testing.T, w *osfs.Watcher, name string, op osfs.WatchOp) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev := <-w.Events():
+			if ev.Name == name && ev.Op&op != 0 {
+				return
+			}
+		case err := <-w.Errors():
+			t.Fatal(err)
+		case <-timeout:
+			t.Fatalf("no %v event for %q", op, name)
+		}
+	}
+}
+
+func TestWatchCreate(t *testing.T) {
+	fs, dir := newTempFS(t)
+
+	if err := fs.Mkdir("watched", 0755); err != nil {
+		t.Fatal(err)
+	}
+	w, err := fs.Watch("watched")
+	if errors.Is(err, osfs.ErrNotSupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if err := os.WriteFile(filepath.Join(dir, "watched", "file"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitEvent(t, w, "watched/file", osfs.WatchCreate)
+
+	if err := os.Remove(filepath.Join(dir, "watched", "file")); err != nil {
+		t.Fatal(err)
+	}
+	waitEvent(t, w, "watched/file", osfs.WatchRemove)
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for range w.Events() {
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+}
+
+func TestWatchRecursive(t *testing.T) {
+	fs, dir := newTempFS(t)
+
+	if err := os.MkdirAll(filepath.Join(dir, "tree", "a"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	w, err := fs.WatchWithOptions("tree", osfs.WatchOptions{Recursive: true})
+	if errors.Is(err, osfs.ErrNotSupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if err := os.WriteFile(filepath.Join(dir, "tree", "a", "file"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitEvent(t, w, "tree/a/file", osfs.WatchCreate)
+
+	// A directory created while watching is watched too.
+	if err := os.Mkdir(filepath.Join(dir, "tree", "b"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	waitEvent(t, w, "tree/b", osfs.WatchCreate)
+	if err := os.WriteFile(filepath.Join(dir, "tree", "b", "file"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitEvent(t, w, "tree/b/file", osfs.WatchCreate)
+}
+
+func TestWatchNotExist(t *testing.T) {
+	fs, _ := newTempFS(t)
+
+	_, err := fs.Watch("missing")
+	if errors.Is(err, osfs.ErrNotSupported) {
+		t.Skip(err)
+	}
+	if !os.IsNotExist(err) {
+		t.Fatalf("Watch(missing) = %v, want not exist", err)
+	}
+}
@@ -0,0 +1,160 @@
+package osfs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const watchFilter = windows.FILE_NOTIFY_CHANGE_FILE_NAME | windows.FILE_NOTIFY_CHANGE_DIR_NAME |
+	windows.FILE_NOTIFY_CHANGE_ATTRIBUTES | windows.FILE_NOTIFY_CHANGE_SIZE |
+	windows.FILE_NOTIFY_CHANGE_LAST_WRITE | windows.FILE_NOTIFY_CHANGE_CREATION
+
+// watchBufferSize is the size of the buffer ReadDirectoryChangesW fills; it
+// must not exceed 64 KiB for directories on network shares.
+const watchBufferSize = 64 << 10
+
+// watcher reads changes to a directory with overlapped
+// ReadDirectoryChangesW calls, waiting on the completion event and on quit,
+// which close signals. A file is watched through its parent directory.
+type watcher struct {
+	h         windows.Handle
+	done      windows.Handle // completion event of the pending read
+	quit      windows.Handle
+	dir       string
+	file      string // name of the watched file in dir, or empty
+	recursive bool
+}
+
+func startWatch(w *Watcher, recursive bool) (*watcher, error) {
+	p, err := windows.UTF16PtrFromString(w.native)
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := windows.GetFileAttributes(p)
+	if err != nil {
+		return nil, err
+	}
+	sys := &watcher{dir: w.native, recursive: recursive}
+	if attrs&windows.FILE_ATTRIBUTE_DIRECTORY == 0 {
+		sys.dir, sys.file = filepath.Split(w.native)
+		sys.dir = filepath.Clean(sys.dir)
+		sys.recursive = false
+		if p, err = windows.UTF16PtrFromString(sys.dir); err != nil {
+			return nil, err
+		}
+	}
+
+	sys.h, err = windows.CreateFile(p, windows.FILE_LIST_DIRECTORY,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE,
+		nil, windows.OPEN_EXISTING, windows.FILE_FLAG_BACKUP_SEMANTICS|windows.FILE_FLAG_OVERLAPPED, 0)
+	if err != nil {
+		return nil, err
+	}
+	if sys.done, err = windows.CreateEvent(nil, 1, 0, nil); err == nil {
+		sys.quit, err = windows.CreateEvent(nil, 1, 0, nil)
+	}
+	if err != nil {
+		sys.release()
+		return nil, os.NewSyscallError("CreateEvent", err)
+	}
+	go sys.run(w)
+	return sys, nil
+}
+
+// close wakes the reading goroutine, which releases the handles once it
+// stops.
+func (sys *watcher) close() error {
+	return windows.SetEvent(sys.quit)
+}
+
+func (sys *watcher) release() {
+	windows.CloseHandle(sys.h)
+	if sys.done != 0 {
+		windows.CloseHandle(sys.done)
+	}
+	if sys.quit != 0 {
+		windows.CloseHandle(sys.quit)
+	}
+}
+
+func (sys *watcher) run(w *Watcher) {
+	defer close(w.exited)
+	defer sys.release()
+
+	// The buffer is made of DWORDs, as ReadDirectoryChangesW requires.
+	buf := make([]uint32, watchBufferSize/4)
+	b := (*[watchBufferSize]byte)(unsafe.Pointer(&buf[0]))[:]
+	ov := &windows.Overlapped{HEvent: sys.done}
+	for {
+		windows.ResetEvent(sys.done)
+		err := windows.ReadDirectoryChanges(sys.h, &b[0], uint32(len(b)), sys.recursive, watchFilter, nil, ov, 0)
+		if err != nil {
+			if !w.closed() {
+				w.fail(os.NewSyscallError("ReadDirectoryChanges", err))
+			}
+			return
+		}
+		r, err := windows.WaitForMultipleObjects([]windows.Handle{sys.done, sys.quit}, false, windows.INFINITE)
+		var n uint32
+		if err != nil || r != windows.WAIT_OBJECT_0 {
+			// Wait for the cancelled read, which still owns buf and ov.
+			windows.CancelIoEx(sys.h, ov)
+			windows.GetOverlappedResult(sys.h, ov, &n, true)
+			if err != nil && !w.closed() {
+				w.fail(os.NewSyscallError("WaitForMultipleObjects", err))
+			}
+			return
+		}
+		if err := windows.GetOverlappedResult(sys.h, ov, &n, false); err != nil {
+			if !w.closed() {
+				w.fail(os.NewSyscallError("ReadDirectoryChanges", err))
+			}
+			return
+		}
+		if n == 0 {
+			if !w.fail(errWatchOverflow) {
+				return
+			}
+			continue
+		}
+		if !sys.handle(w, b[:n]) {
+			return
+		}
+	}
+}
+
+// handle reports the FILE_NOTIFY_INFORMATION records in b, returning false
+// once the watcher is closed.
+func (sys *watcher) handle(w *Watcher, b []byte) bool {
+	for off := uint32(0); ; {
+		info := (*windows.FileNotifyInformation)(unsafe.Pointer(&b[off]))
+		n := info.FileNameLength / 2
+		name := windows.UTF16ToString((*[1 << 15]uint16)(unsafe.Pointer(&info.FileName))[:n:n])
+
+		var op WatchOp
+		switch info.Action {
+		case windows.FILE_ACTION_ADDED, windows.FILE_ACTION_RENAMED_NEW_NAME:
+			op = WatchCreate
+		case windows.FILE_ACTION_REMOVED:
+			op = WatchRemove
+		case windows.FILE_ACTION_MODIFIED:
+			op = WatchWrite
+		case windows.FILE_ACTION_RENAMED_OLD_NAME:
+			op = WatchRename
+		}
+		if op != 0 && (sys.file == "" || strings.EqualFold(name, sys.file)) {
+			if !w.send(filepath.Join(sys.dir, name), op) {
+				return false
+			}
+		}
+
+		if info.NextEntryOffset == 0 {
+			return true
+		}
+		off += info.NextEntryOffset
+	}
+}
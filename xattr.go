@@ -0,0 +1,53 @@
+package osfs
+
+import "errors"
+
+// ErrNoXattr is returned, wrapped in an *os.PathError, by GetXattr and
+// RemoveXattr when the file has no extended attribute of the given name.
+var ErrNoXattr = errors.New("no such extended attribute")
+
+// GetXattr returns the value of the extended attribute attr of the named
+// file, following symbolic links. Extended attributes are available on
+// Linux, macOS, FreeBSD and NetBSD. On Linux, FreeBSD and NetBSD attr
+// includes its namespace, as in "user.comment", and unprivileged callers
+// are limited to the "user" namespace; macOS names have no namespace.
+// Elsewhere, and on filesystems without extended attributes, an error
+// wrapping ErrNotSupported is returned.
+func (fs *FileSystem) GetXattr(name, attr string) ([]byte, error) {
+	path, err := fs.fixPathErr("getxattr", name)
+	if err != nil {
+		return nil, err
+	}
+	return getXattr(path, attr)
+}
+
+// SetXattr sets the extended attribute attr of the named file to data,
+// creating or replacing it and following symbolic links. See GetXattr for
+// the form of attr.
+func (fs *FileSystem) SetXattr(name, attr string, data []byte) error {
+	path, err := fs.fixPathErr("setxattr", name)
+	if err != nil {
+		return err
+	}
+	return setXattr(path, attr, data)
+}
+
+// ListXattr returns the names of the extended attributes of the named
+// file, following symbolic links. The order is filesystem dependent.
+func (fs *FileSystem) ListXattr(name string) ([]string, error) {
+	path, err := fs.fixPathErr("listxattr", name)
+	if err != nil {
+		return nil, err
+	}
+	return listXattr(path)
+}
+
+// RemoveXattr removes the extended attribute attr from the named file,
+// following symbolic links.
+func (fs *FileSystem) RemoveXattr(name, attr string) error {
+	path, err := fs.fixPathErr("removexattr", name)
+	if err != nil {
+		return err
+	}
+	return removeXattr(path, attr)
+}
@@ -0,0 +1,9 @@
+//go:build darwin || freebsd || netbsd
+// +build darwin freebsd netbsd
+
+package osfs
+
+import "golang.org/x/sys/unix"
+
+// errnoNoXattr is the error for a missing extended attribute.
+const errnoNoXattr = unix.ENOATTR
@@ -0,0 +1,6 @@
+package osfs
+
+import "golang.org/x/sys/unix"
+
+// errnoNoXattr is the error for a missing extended attribute.
+const errnoNoXattr = unix.ENODATA
@@ -0,0 +1,22 @@
+//go:build !darwin && !freebsd && !linux && !netbsd
+// +build !darwin,!freebsd,!linux,!netbsd
+
+package osfs
+
+import "os"
+
+func getXattr(path, attr string) ([]byte, error) {
+	return nil, &os.PathError{Op: "getxattr", Path: path, Err: ErrNotSupported}
+}
+
+func setXattr(path, attr string, data []byte) error {
+	return &os.PathError{Op: "setxattr", Path: path, Err: ErrNotSupported}
+}
+
+func listXattr(path string) ([]string, error) {
+	return nil, &os.PathError{Op: "listxattr", Path: path, Err: ErrNotSupported}
+}
+
+func removeXattr(path, attr string) error {
+	return &os.PathError{Op: "removexattr", Path: path, Err: ErrNotSupported}
+}
@@ -0,0 +1,81 @@
+//go:build darwin || freebsd || linux || netbsd
+// +build darwin freebsd linux netbsd
+
+package osfs
+
+import (
+	"os"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// xattrError wraps err for op on path, mapping the errors for a missing
+// attribute and an unsupported filesystem to ErrNoXattr and ErrNotSupported.
+func xattrError(op, path string, err error) error {
+	switch err {
+	case errnoNoXattr:
+		err = ErrNoXattr
+	case unix.ENOTSUP:
+		err = ErrNotSupported
+	}
+	return &os.PathError{Op: op, Path: path, Err: err}
+}
+
+func getXattr(path, attr string) ([]byte, error) {
+	for {
+		// Ask for the size first; the attribute may still grow before it
+		// is read, which ERANGE reports.
+		n, err := unix.Getxattr(path, attr, nil)
+		if err != nil {
+			return nil, xattrError("getxattr", path, err)
+		}
+		buf := make([]byte, n)
+		if n == 0 {
+			return buf, nil
+		}
+		n, err = unix.Getxattr(path, attr, buf)
+		if err == unix.ERANGE {
+			continue
+		}
+		if err != nil {
+			return nil, xattrError("getxattr", path, err)
+		}
+		return buf[:n], nil
+	}
+}
+
+func setXattr(path, attr string, data []byte) error {
+	if err := unix.Setxattr(path, attr, data, 0); err != nil {
+		return xattrError("setxattr", path, err)
+	}
+	return nil
+}
+
+func listXattr(path string) ([]string, error) {
+	for {
+		n, err := unix.Listxattr(path, nil)
+		if err != nil {
+			return nil, xattrError("listxattr", path, err)
+		}
+		if n == 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n)
+		n, err = unix.Listxattr(path, buf)
+		if err == unix.ERANGE {
+			continue
+		}
+		if err != nil {
+			return nil, xattrError("listxattr", path, err)
+		}
+		return strings.Split(strings.TrimSuffix(string(buf[:n]), "\x00"), "\x00"), nil
+	}
+}
+
+func removeXattr(path, attr string) error {
+	if err := unix.Removexattr(path, attr); err != nil {
+		return xattrError("removexattr", path, err)
+	}
+	return nil
+}
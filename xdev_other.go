@@ -0,0 +1,15 @@
+//go:build !windows
+// +build !windows
+
+package osfs
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isCrossDevice reports whether err is the failure of a rename between two
+// filesystems.
+func isCrossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}